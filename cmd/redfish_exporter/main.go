@@ -15,6 +15,9 @@ func main() {
 
 	rootCmd.AddCommand(
 		cmds.NewServeCmd(),
+		cmds.NewCheckConfigCmd(),
+		cmds.NewRecordCmd(),
+		cmds.NewProbeCmd(),
 		cmds.NewVersionCmd(),
 	)
 
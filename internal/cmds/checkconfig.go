@@ -0,0 +1,71 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/customcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/spf13/cobra"
+)
+
+func NewCheckConfigCmd() *cobra.Command {
+	var cfg string
+	cmd := &cobra.Command{
+		Use:   "check-config",
+		Short: "Validate the configuration without starting the exporter",
+		Long: `Validate the configuration without starting the exporter
+
+Reports unknown keys, invalid values and unreadable files with the line they
+occur on and exits non-zero if any are found.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !checkConfig(cfg) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg, "config", "", "config file (default ./config.yaml if present)")
+	return cmd
+}
+
+func checkConfig(configFile string) bool {
+	var opts []config.Option
+	if configFile != "" {
+		opts = append(opts, config.WithFilePath(configFile))
+	}
+
+	_, problems, err := config.Check(opts, validateClient, validateCustomMetrics)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		return false
+	}
+
+	fmt.Println("Config OK")
+	return true
+}
+
+func validateClient(cfg config.Config) config.Problems {
+	if _, err := redfish.NewClientConfig(cfg, nil, nil); err != nil {
+		return config.Problems{{Key: "host", Message: err.Error()}}
+	}
+	return nil
+}
+
+func validateCustomMetrics(cfg config.Config) config.Problems {
+	var problems config.Problems
+	for i, metric := range cfg.CustomMetrics {
+		if err := customcollector.Validate(metric); err != nil {
+			problems = append(problems, config.Problem{Key: fmt.Sprintf("customMetrics[%d]", i), Message: err.Error()})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,98 @@
+package cmds
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/chassiscollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/customcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/oemcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/powerequipmentcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/spf13/pflag"
+	"github.com/stmcginnis/gofish"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// configOptions provides the logger and the config read from configFile,
+// the environment and flags.
+func configOptions(configFile string, flags *pflag.FlagSet, extra ...config.Option) fx.Option {
+	configOptionProvider := func() []config.Option {
+		opts := []config.Option{config.WithFlags(flags)}
+		if configFile != "" {
+			opts = append(opts, config.WithFilePath(configFile))
+		}
+		return append(opts, extra...)
+	}
+
+	return fx.Options(
+		fx.WithLogger(func(log *log.Logger) fxevent.Logger {
+			return &fxevent.ZapLogger{Logger: log.Zap()}
+		}),
+		fx.Provide(
+			log.New,
+			configOptionProvider,
+			config.New,
+		),
+	)
+}
+
+// collectorRegistrations lists the collectors by the name they report in
+// redfish_collector_scrape_status.
+var collectorRegistrations = []struct {
+	name     string
+	register interface{}
+}{
+	{"chassis", chassiscollector.Register},
+	{"power_equipment", powerequipmentcollector.Register},
+	{"oem", oemcollector.Register},
+	{"custom", customcollector.Register},
+}
+
+// collectorOptions provides the redfish client and registers the named
+// collectors, or all of them if none are named, with the
+// collectors.Registry.
+func collectorOptions(names ...string) fx.Option {
+	invokes := []interface{}{
+		redfish.Start,
+		collectors.RegisterScrapeStatus,
+	}
+	for _, registration := range collectorRegistrations {
+		if len(names) == 0 || slices.Contains(names, registration.name) {
+			invokes = append(invokes, registration.register)
+		}
+	}
+
+	return fx.Options(
+		fx.Provide(
+			tracing.New,
+			redfish.NewRequestMetrics,
+			redfish.NewClientConfig,
+			redfish.NewClient,
+			redfish.NewEventSubscription,
+			collectors.NewRegistry,
+			collectors.NewScrapeStatus,
+			chassiscollector.New,
+			powerequipmentcollector.New,
+			oemcollector.New,
+			customcollector.New,
+		),
+		fx.Invoke(invokes...),
+	)
+}
+
+// wrapTransport wraps the transport the redfish client sends its requests
+// through, e.g. to record them.
+func wrapTransport(wrap func(http.RoundTripper) http.RoundTripper) fx.Option {
+	return fx.Decorate(func(clientConfig *gofish.ClientConfig) *gofish.ClientConfig {
+		httpClient := *clientConfig.HTTPClient
+		httpClient.Transport = wrap(httpClient.Transport)
+		clientConfig.HTTPClient = &httpClient
+		return clientConfig
+	})
+}
@@ -0,0 +1,291 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/prometheus"
+	"github.com/FreekingDean/redfish_exporter/internal/transport"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/dig"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type probeOptions struct {
+	config     string
+	collectors []string
+	timeout    time.Duration
+}
+
+func NewProbeCmd() *cobra.Command {
+	var opts probeOptions
+	cmd := &cobra.Command{
+		Use:   "probe [target]",
+		Short: "Run the collectors once against a BMC and report the outcome",
+		Long: `Run the collectors once against a BMC and report the outcome
+
+Prints the metrics, every request sent to the BMC with its status code and
+duration, and the errors and warnings logged by the collectors, then exits.
+The exit code is 1 if any errors were logged or the metrics could not all
+be gathered.
+
+The target overrides host.endpoint, the remaining settings such as
+credentials are read like with serve.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var target string
+			if len(args) > 0 {
+				target = args[0]
+			}
+			ok, err := probe(os.Stdout, target, opts, cmd.Flags())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+		},
+	}
+
+	names := make([]string, 0, len(collectorRegistrations))
+	for _, registration := range collectorRegistrations {
+		names = append(names, registration.name)
+	}
+	cmd.Flags().StringVar(&opts.config, "config", "", "config file (default ./config.yaml if present)")
+	cmd.Flags().StringSliceVar(&opts.collectors, "collectors", nil, fmt.Sprintf("collectors to run, of %s (default all enabled)", strings.Join(names, ", ")))
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", time.Minute, "deadline for running the collectors")
+	config.AddFlags(cmd.Flags())
+	return cmd
+}
+
+// probe runs the collectors once and writes the report to w. It reports
+// whether the collectors ran without errors.
+func probe(w io.Writer, target string, opts probeOptions, flags *pflag.FlagSet) (bool, error) {
+	// Nothing receives the events of a one-off run.
+	extra := []config.Option{config.WithValue("events.enabled", false)}
+	if target != "" {
+		extra = append(extra, config.WithValue("host.endpoint", target))
+	}
+	for _, name := range opts.collectors {
+		switch name {
+		case "power_equipment":
+			extra = append(extra, config.WithValue("collectors.powerEquipment", true))
+		case "oem":
+			extra = append(extra, config.WithValue("collectors.oem", true))
+		case "chassis", "custom":
+		default:
+			return false, fmt.Errorf("unknown collector %q", name)
+		}
+	}
+
+	logs := newLogBuffer(zapcore.WarnLevel)
+	requests := &requestLog{}
+	var (
+		cfg      config.Config
+		registry *collectors.Registry
+		tracer   trace.Tracer
+	)
+	app := fx.New(
+		configOptions(opts.config, flags, extra...),
+		fx.Replace(&log.Logger{Logger: zap.New(logs)}),
+		// Errors are reported once by probe rather than by every fx event.
+		fx.NopLogger,
+		collectorOptions(opts.collectors...),
+		wrapTransport(requests.transport),
+		fx.Populate(&cfg, &registry, &tracer),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	// The client connects while the app is built, so failing to reach the
+	// BMC is reported here.
+	startErr := app.Err()
+	if startErr == nil {
+		startErr = app.Start(ctx)
+		defer app.Stop(context.Background())
+	}
+
+	ok := startErr == nil
+	var gatherErr error
+	if startErr == nil {
+		ctx, span := tracer.Start(ctx, "probe")
+		// The families gathered before an error, e.g. of inconsistent
+		// series, are still printed.
+		var families []*dto.MetricFamily
+		families, gatherErr = prometheus.EncodingGatherer(promclient.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return registry.GatherContext(ctx)
+		}), cfg.Metrics).Gather()
+		span.End()
+		if gatherErr != nil {
+			ok = false
+		}
+
+		fmt.Fprintln(w, "# Metrics")
+		for _, family := range families {
+			if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+				return false, err
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "# Requests")
+	requests.write(w)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "# Errors")
+	if startErr != nil {
+		fmt.Fprintf(w, "error\t%v\n", dig.RootCause(startErr))
+	}
+	var gatherErrs promclient.MultiError
+	if !errors.As(gatherErr, &gatherErrs) && gatherErr != nil {
+		gatherErrs = promclient.MultiError{gatherErr}
+	}
+	for _, err := range gatherErrs {
+		fmt.Fprintf(w, "error\t%v\n", err)
+	}
+	for _, entry := range logs.all() {
+		fmt.Fprintf(w, "%s\t%s", entry.Level, entry.Message)
+		fields := zapcore.NewMapObjectEncoder()
+		for _, field := range entry.fields {
+			field.AddTo(fields)
+		}
+		keys := make([]string, 0, len(fields.Fields))
+		for key := range fields.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, " %s=%v", key, fields.Fields[key])
+		}
+		fmt.Fprintln(w)
+		if entry.Level >= zapcore.ErrorLevel {
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+type logEntry struct {
+	zapcore.Entry
+	fields []zapcore.Field
+}
+
+// logBuffer is a zapcore.Core that keeps the entries logged at or above its
+// level so they can be reported once the collectors ran.
+type logBuffer struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+
+	mu      *sync.Mutex
+	entries *[]logEntry
+}
+
+func newLogBuffer(level zapcore.LevelEnabler) *logBuffer {
+	return &logBuffer{LevelEnabler: level, mu: &sync.Mutex{}, entries: &[]logEntry{}}
+}
+
+func (b *logBuffer) With(fields []zapcore.Field) zapcore.Core {
+	with := *b
+	with.fields = append(b.fields[:len(b.fields):len(b.fields)], fields...)
+	return &with
+}
+
+func (b *logBuffer) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if b.Enabled(entry.Level) {
+		return checked.AddCore(entry, b)
+	}
+	return checked
+}
+
+func (b *logBuffer) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	*b.entries = append(*b.entries, logEntry{Entry: entry, fields: append(b.fields[:len(b.fields):len(b.fields)], fields...)})
+	return nil
+}
+
+func (b *logBuffer) Sync() error {
+	return nil
+}
+
+func (b *logBuffer) all() []logEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]logEntry(nil), *b.entries...)
+}
+
+type probeRequest struct {
+	method   string
+	uri      string
+	status   int
+	duration time.Duration
+	err      error
+}
+
+// requestLog records the requests sent to the BMC in the order they were
+// sent.
+type requestLog struct {
+	mu       sync.Mutex
+	requests []*probeRequest
+}
+
+func (l *requestLog) transport(next http.RoundTripper) http.RoundTripper {
+	return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		request := &probeRequest{method: req.Method, uri: req.URL.RequestURI()}
+		l.mu.Lock()
+		l.requests = append(l.requests, request)
+		l.mu.Unlock()
+
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		request.duration = time.Since(start)
+		request.err = err
+		if resp != nil {
+			request.status = resp.StatusCode
+		}
+		return resp, err
+	})
+}
+
+func (l *requestLog) write(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tURI\tSTATUS\tDURATION\tERROR")
+	for _, request := range l.requests {
+		status, errText := "-", ""
+		if request.status != 0 {
+			status = fmt.Sprint(request.status)
+		}
+		if request.err != nil {
+			errText = request.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", request.method, request.uri, status, request.duration.Round(time.Millisecond), errText)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,104 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/fx"
+)
+
+type recordOptions struct {
+	config  string
+	target  string
+	out     string
+	full    bool
+	depth   int
+	timeout time.Duration
+}
+
+func NewRecordCmd() *cobra.Command {
+	var opts recordOptions
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Capture a BMC's Redfish tree for replay",
+		Long: `Capture a BMC's Redfish tree for replay
+
+Runs the collectors once and writes every resource they fetched to the
+directory given by --out, laid out like the DMTF mockups. With --full, every
+resource linked from the service root is captured instead, up to --depth
+links deep.
+
+Serial numbers, MAC addresses and IP addresses are replaced with made up
+values. Review the recording before sharing it all the same, e.g. for host
+names or asset tags.
+
+The recording can be served with serve --replay.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := record(opts, cmd.Flags()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.config, "config", "", "config file (default ./config.yaml if present)")
+	cmd.Flags().StringVar(&opts.target, "target", "", "endpoint of the BMC, overrides host.endpoint")
+	cmd.Flags().StringVar(&opts.out, "out", "", "directory to write the recording to")
+	cmd.Flags().BoolVar(&opts.full, "full", false, "capture the full tree by following links instead of what the collectors fetch")
+	cmd.Flags().IntVar(&opts.depth, "depth", 8, "number of links to follow from the service root with --full")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 2*time.Minute, "deadline for running the collectors")
+	_ = cmd.MarkFlagRequired("out")
+	config.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func record(opts recordOptions, flags *pflag.FlagSet) error {
+	// Nothing receives the events of a one-off run.
+	extra := []config.Option{config.WithValue("events.enabled", false)}
+	if opts.target != "" {
+		extra = append(extra, config.WithValue("host.endpoint", opts.target))
+	}
+
+	recorder := redfishtest.NewRecorder()
+	var (
+		registry *collectors.Registry
+		client   *redfish.Client
+	)
+	app := fx.New(
+		configOptions(opts.config, flags, extra...),
+		collectorOptions(),
+		wrapTransport(recorder.Transport),
+		fx.Populate(&registry, &client),
+	)
+	if err := app.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(context.Background())
+
+	if opts.full {
+		recorder.Crawl(client.Get, opts.depth)
+	} else if _, err := registry.GatherContext(ctx); err != nil {
+		return err
+	}
+
+	resources := redfishtest.Sanitize(recorder.Resources())
+	if err := redfishtest.WriteFixture(opts.out, resources); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d resources to %s\n", len(resources), opts.out)
+	return nil
+}
@@ -1,64 +1,104 @@
 package cmds
 
 import (
-	"github.com/FreekingDean/redfish_exporter/internal/collectors/chassiscollector"
+	"fmt"
+	"os"
+
 	"github.com/FreekingDean/redfish_exporter/internal/config"
-	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/poller"
 	"github.com/FreekingDean/redfish_exporter/internal/prometheus"
 	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
 	"github.com/FreekingDean/redfish_exporter/internal/server"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.uber.org/fx"
-	"go.uber.org/fx/fxevent"
 )
 
 func NewServeCmd() *cobra.Command {
-	var cfg string
+	var cfg, replay string
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start the Redfish Exporter",
-		Run:   func(cmd *cobra.Command, args []string) { serve(cfg) },
+		Long: `Start the Redfish Exporter
+
+Every config key can be set with a flag, e.g. --host.endpoint, or with an
+environment variable, e.g. REDFISH_EXPORTER_HOST_ENDPOINT. Flags take
+precedence over environment variables, which take precedence over the config
+file. Without --config, ./config.yaml is read if it exists.
+
+With --replay, metrics are served from a tree captured with the record
+command instead of a BMC.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := serve(cfg, replay, cmd.Flags()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
 	}
 
-	cmd.PersistentFlags().StringVar(&cfg, "config", "./config.yaml", "config file [./config.yaml]")
+	cmd.PersistentFlags().StringVar(&cfg, "config", "", "config file (default ./config.yaml if present)")
+	cmd.Flags().StringVar(&replay, "replay", "", "serve the Redfish tree recorded in this directory instead of the configured host")
+	config.AddFlags(cmd.Flags())
 	return cmd
 }
 
-func serve(configFile string) {
-	configOptionProvider := func() []config.Option {
-		return []config.Option{
-			config.WithFilePath(configFile),
+func serve(configFile, replay string, flags *pflag.FlagSet) error {
+	var opts []config.Option
+	if replay != "" {
+		resources, err := redfishtest.LoadFixture(replay)
+		if err != nil {
+			return err
 		}
+		bmc := redfishtest.NewServer(resources)
+		defer bmc.Close()
+		opts = replayOptions(bmc)
 	}
+
 	app := fx.New(
 		// Initialize FX
-		fx.WithLogger(func(log *log.Logger) fxevent.Logger {
-			return &fxevent.ZapLogger{Logger: log.Zap()}
-		}),
+		configOptions(configFile, flags, opts...),
 		fx.RecoverFromPanics(),
 
 		// Provide Dependencies
+		collectorOptions(),
 		fx.Provide(
-			log.New,
-			configOptionProvider,
-			config.New,
-			redfish.NewClientConfig,
-			redfish.NewClient,
 			server.NewMux,
 			server.New,
-			prometheus.NewRegistry,
-			chassiscollector.New,
+			prometheus.NewRuntimeRegistry,
+			poller.New,
+			redfish.NewEventMetrics,
+			redfish.NewEventStream,
 		),
 
 		// Invoke Service
 		fx.Invoke(
-			redfish.Start,
 			prometheus.RegisterBasicCollectors,
-			chassiscollector.Register,
+			prometheus.RegisterRequestMetrics,
+			prometheus.RegisterEventMetrics,
 			prometheus.RegisterHandler,
+			server.RegisterEventReceiver,
+			redfish.StartEventStream,
+			poller.Start,
 			server.Run,
+			// After the server is listening for the events.
+			redfish.Subscribe,
 		),
 	)
 
 	app.Run()
+	return nil
+}
+
+// replayOptions point the exporter at a mock BMC, overriding the host
+// configured otherwise.
+func replayOptions(bmc *redfishtest.Server) []config.Option {
+	return []config.Option{
+		config.WithValue("host.endpoint", bmc.URL),
+		config.WithValue("host.username", bmc.Username),
+		config.WithValue("host.password", bmc.Password),
+		config.WithValue("host.usernameFile", ""),
+		config.WithValue("host.passwordFile", ""),
+		config.WithValue("host.basicAuth", true),
+	}
 }
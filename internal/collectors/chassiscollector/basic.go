@@ -1,8 +1,6 @@
 package chassiscollector
 
 import (
-	"fmt"
-
 	"github.com/FreekingDean/redfish_exporter/internal/collectors"
 	"github.com/FreekingDean/redfish_exporter/internal/redfish"
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,17 +8,15 @@ import (
 
 func basicChassisMetrics() map[string]*prometheus.Desc {
 	return map[string]*prometheus.Desc{
-		healthMetric: prometheus.NewDesc(
+		healthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, "health"),
-			collectors.HealthHelp("chassis"),
+			"chassis",
 			labels,
-			nil, // Constant Labels
 		),
-		stateMetric: prometheus.NewDesc(
+		stateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, "state"),
-			fmt.Sprintf("state of chassis,%s", collectors.CommonStateHelp),
+			"chassis",
 			labels,
-			nil, // Constant Labels
 		),
 		modelInfoMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, "model_info"),
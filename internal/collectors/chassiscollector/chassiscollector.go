@@ -5,9 +5,13 @@ import (
 	"sync"
 
 	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
 	"github.com/FreekingDean/redfish_exporter/internal/log"
 	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
@@ -25,31 +29,34 @@ var (
 
 type collectorFunc func(chan<- prometheus.Metric, *redfish.Chassis)
 
+// namedCollectorFunc names a collectorFunc for its span.
+type namedCollectorFunc struct {
+	name    string
+	collect collectorFunc
+}
+
 type Collector struct {
-	logger         *log.Logger
-	redfish        *redfish.Client
-	metrics        map[string]*prometheus.Desc
-	scrapeStatus   *prometheus.GaugeVec
-	collectorFuncs []collectorFunc
+	logger             *log.Logger
+	redfish            *redfish.Client
+	metrics            map[string]*prometheus.Desc
+	scrapeStatus       *collectors.ScrapeStatus
+	tracer             trace.Tracer
+	collectorFuncs     []namedCollectorFunc
+	legacyNullReadings bool
 }
 
-func New(logger *log.Logger, client *redfish.Client) *Collector {
+func New(logger *log.Logger, client *redfish.Client, cfg config.Config, scrapeStatus *collectors.ScrapeStatus, tracer trace.Tracer) *Collector {
 	return &Collector{
-		logger:  logger,
-		redfish: client,
-		metrics: make(map[string]*prometheus.Desc),
-		scrapeStatus: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Namespace: collectors.Namespace,
-				Name:      "collector_scrape_status",
-				Help:      "collector_scrape_status",
-			},
-			[]string{"collector"},
-		),
+		logger:             logger,
+		redfish:            client,
+		metrics:            make(map[string]*prometheus.Desc),
+		scrapeStatus:       scrapeStatus,
+		tracer:             tracer,
+		legacyNullReadings: cfg.Metrics.LegacyNullReadings,
 	}
 }
 
-func Register(collector *Collector, registry *prometheus.Registry, lc fx.Lifecycle) {
+func Register(collector *Collector, registry *collectors.Registry, lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			metricGroups := []map[string]*prometheus.Desc{
@@ -64,11 +71,11 @@ func Register(collector *Collector, registry *prometheus.Registry, lc fx.Lifecyc
 					collector.metrics[metricName] = metric
 				}
 			}
-			collector.collectorFuncs = []collectorFunc{
-				collector.collectBasicMetrics,
-				collector.collectThermalMetrics,
-				collector.collectPowerMetrics,
-				collector.collectNetworkMetrics,
+			collector.collectorFuncs = []namedCollectorFunc{
+				{"collectBasicMetrics", collector.collectBasicMetrics},
+				{"collectThermalMetrics", collector.collectThermalMetrics},
+				{"collectPowerMetrics", collector.collectPowerMetrics},
+				{"collectNetworkMetrics", collector.collectNetworkMetrics},
 			}
 
 			return registry.Register(collector)
@@ -80,15 +87,29 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	for _, metric := range c.metrics {
 		ch <- metric
 	}
-	c.scrapeStatus.Describe(ch)
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *Collector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Debug("Collecting chassis metrics")
+	ctx, span := c.tracer.Start(ctx, "chassis")
+	defer span.End()
+
+	client, err := c.redfish.WithContext(ctx)
+	if err != nil {
+		c.logger.Error("Failed to connect to redfish service", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("chassis").Set(float64(0))
+		return
+	}
 
-	chassiss, err := c.redfish.GetService().Chassis()
+	chassiss, err := client.GetService().Chassis()
 	if err != nil {
 		c.logger.Error("Failed to get chassis", log.Error(err))
+		tracing.Fail(span, err)
 		c.scrapeStatus.WithLabelValues("chassis").Set(float64(0))
 		return
 	}
@@ -99,7 +120,19 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				collectorFunc(ch, chassis)
+				ctx, span := c.tracer.Start(ctx, collectorFunc.name, trace.WithAttributes(
+					attribute.String("chassis_id", chassis.ID),
+				))
+				defer span.End()
+				// The requests of the collectorFunc are bound to its span,
+				// so their spans are its children.
+				client, err := client.WithContext(ctx)
+				if err != nil {
+					c.logger.Error("Failed to connect to redfish service", log.Error(err))
+					tracing.Fail(span, err)
+					return
+				}
+				collectorFunc.collect(ch, client.Chassis(chassis))
 			}()
 		}
 	}
@@ -108,3 +141,26 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	c.logger.Debug("Finished collecting chassis metrics")
 	c.scrapeStatus.WithLabelValues("chassis").Set(float64(1))
 }
+
+// reading returns property from readings and whether a series should be
+// exported for it, which is only the case if the BMC reported a value unless
+// null readings are exported as 0 like earlier versions did.
+func (c *Collector) reading(readings redfish.Readings, property string) (float64, bool) {
+	if value, ok := readings.Get(property); ok {
+		return value, true
+	}
+	return 0, c.legacyNullReadings
+}
+
+// skipReadings reports whether the readings of a sensor in state are
+// meaningless, because the sensor is absent.
+func (c *Collector) skipReadings(state redfish.State) bool {
+	return state == redfish.StateAbsent && !c.legacyNullReadings
+}
+
+// collectThresholds exports the thresholds of a sensor whose reading is
+// exported as the metric reading. Thresholds the BMC does not report are
+// skipped, also with legacy null readings, as a threshold of 0 would alert.
+func (c *Collector) collectThresholds(ch chan<- prometheus.Metric, reading string, readings redfish.Readings, labelValues []string) {
+	collectors.CollectThresholds(ch, c.metrics, reading, readings.Get, labelValues...)
+}
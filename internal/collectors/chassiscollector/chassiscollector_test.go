@@ -0,0 +1,625 @@
+package chassiscollector_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/chassiscollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const (
+	chassisURI = "/redfish/v1/Chassis/1U"
+	thermalURI = chassisURI + "/Thermal"
+	powerURI   = chassisURI + "/Power"
+	adapterURI = chassisURI + "/NetworkAdapters/NIC1"
+	portURI    = adapterURI + "/NetworkPorts/1"
+)
+
+type harness struct {
+	server   *redfishtest.Server
+	registry *collectors.Registry
+}
+
+func newHarness(t *testing.T, cfg config.Config, setup func(*redfishtest.Server)) *harness {
+	t.Helper()
+	return newTracedHarness(t, cfg, setup, noop.NewTracerProvider().Tracer(""))
+}
+
+func newTracedHarness(t *testing.T, cfg config.Config, setup func(*redfishtest.Server), tracer trace.Tracer) *harness {
+	t.Helper()
+
+	server := redfishtest.NewServer(redfishtest.DefaultTree())
+	t.Cleanup(server.Close)
+	if setup != nil {
+		setup(server)
+	}
+
+	cfg.Host.Endpoint = server.URL
+	cfg.Host.Username = server.Username
+	cfg.Host.Password = server.Password
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := collectors.NewRegistry()
+	scrapeStatus := collectors.NewScrapeStatus()
+	lc := fxtest.NewLifecycle(t)
+	collectors.RegisterScrapeStatus(scrapeStatus, registry, lc)
+	chassiscollector.Register(chassiscollector.New(logger, client, cfg, scrapeStatus, tracer), registry, lc)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return &harness{server: server, registry: registry}
+}
+
+// compare scrapes with ctx, compares the named metric families and checks
+// that the absent ones were not exported.
+func (h *harness) compare(t *testing.T, ctx context.Context, expected string, names, absent []string) {
+	t.Helper()
+
+	families, err := h.registry.GatherContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if slices.Contains(absent, family.GetName()) {
+			t.Errorf("unexpected metric %s", family.GetName())
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return families, nil
+	})
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+}
+
+func update(server *redfishtest.Server, uri string, f func(redfishtest.Resource)) {
+	resource := redfishtest.DefaultTree()[uri]
+	f(resource)
+	server.Set(uri, resource)
+}
+
+func member(resource redfishtest.Resource, list string) redfishtest.Resource {
+	return resource[list].([]interface{})[0].(redfishtest.Resource)
+}
+
+func TestCollector(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      config.Config
+		setup    func(*redfishtest.Server)
+		names    []string
+		absent   []string
+		expected string
+	}{
+		{
+			name:  "basic",
+			names: []string{"redfish_chassis_health", "redfish_chassis_state"},
+			expected: `
+# HELP redfish_chassis_health health of chassis,1(OK),2(Warning),3(Critical)
+# TYPE redfish_chassis_health gauge
+redfish_chassis_health{chassis_id="Computer System Chassis",resource="1U"} 1
+# HELP redfish_chassis_state state of chassis,1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)
+# TYPE redfish_chassis_state gauge
+redfish_chassis_state{chassis_id="Computer System Chassis",resource="1U"} 1
+`,
+		},
+		{
+			name: "basic warning",
+			setup: func(s *redfishtest.Server) {
+				update(s, chassisURI, func(r redfishtest.Resource) {
+					r["Status"] = redfishtest.Resource{"Health": "Warning", "State": "StandbySpare"}
+				})
+			},
+			names: []string{"redfish_chassis_health", "redfish_chassis_state"},
+			expected: `
+# HELP redfish_chassis_health health of chassis,1(OK),2(Warning),3(Critical)
+# TYPE redfish_chassis_health gauge
+redfish_chassis_health{chassis_id="Computer System Chassis",resource="1U"} 2
+# HELP redfish_chassis_state state of chassis,1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)
+# TYPE redfish_chassis_state gauge
+redfish_chassis_state{chassis_id="Computer System Chassis",resource="1U"} 4
+`,
+		},
+		{
+			name: "thermal",
+			names: []string{
+				"redfish_chassis_temperature_celsius",
+				"redfish_chassis_temperature_celsius_upper_threshold_critical",
+				"redfish_chassis_temperature_celsius_upper_threshold_fatal",
+				"redfish_chassis_temperature_sensor_health",
+			},
+			expected: `
+# HELP redfish_chassis_temperature_celsius celcius temperature of the chassis component
+# TYPE redfish_chassis_temperature_celsius gauge
+redfish_chassis_temperature_celsius{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 41
+# HELP redfish_chassis_temperature_celsius_upper_threshold_critical threshold above the normal range that is not considered fatal
+# TYPE redfish_chassis_temperature_celsius_upper_threshold_critical gauge
+redfish_chassis_temperature_celsius_upper_threshold_critical{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 90
+# HELP redfish_chassis_temperature_celsius_upper_threshold_fatal threshold above the normal range that is considered fatal
+# TYPE redfish_chassis_temperature_celsius_upper_threshold_fatal gauge
+redfish_chassis_temperature_celsius_upper_threshold_fatal{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 95
+# HELP redfish_chassis_temperature_sensor_health health of chassis.temprature_sensor,1(OK),2(Warning),3(Critical)
+# TYPE redfish_chassis_temperature_sensor_health gauge
+redfish_chassis_temperature_sensor_health{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 1
+`,
+		},
+		{
+			name: "thermal null reading",
+			setup: func(s *redfishtest.Server) {
+				update(s, thermalURI, func(r redfishtest.Resource) {
+					member(r, "Temperatures")["ReadingCelsius"] = nil
+				})
+			},
+			names:  []string{"redfish_chassis_temperature_sensor_health"},
+			absent: []string{"redfish_chassis_temperature_celsius"},
+			expected: `
+# HELP redfish_chassis_temperature_sensor_health health of chassis.temprature_sensor,1(OK),2(Warning),3(Critical)
+# TYPE redfish_chassis_temperature_sensor_health gauge
+redfish_chassis_temperature_sensor_health{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 1
+`,
+		},
+		{
+			name: "thermal null reading legacy",
+			cfg:  config.Config{Metrics: config.Metrics{LegacyNullReadings: true}},
+			setup: func(s *redfishtest.Server) {
+				update(s, thermalURI, func(r redfishtest.Resource) {
+					member(r, "Temperatures")["ReadingCelsius"] = nil
+				})
+			},
+			names: []string{"redfish_chassis_temperature_celsius"},
+			expected: `
+# HELP redfish_chassis_temperature_celsius celcius temperature of the chassis component
+# TYPE redfish_chassis_temperature_celsius gauge
+redfish_chassis_temperature_celsius{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 0
+`,
+		},
+		{
+			name:  "thermal missing thresholds legacy",
+			cfg:   config.Config{Metrics: config.Metrics{LegacyNullReadings: true}},
+			names: []string{"redfish_chassis_temperature_celsius_upper_threshold_critical"},
+			absent: []string{
+				"redfish_chassis_temperature_celsius_upper_threshold_non_critical",
+				"redfish_chassis_temperature_celsius_lower_threshold_critical",
+				"redfish_chassis_temperature_celsius_lower_threshold_non_critical",
+				"redfish_chassis_temperature_celsius_lower_threshold_fatal",
+				"redfish_chassis_power_voltage_volts_upper_threshold_fatal",
+				"redfish_chassis_power_voltage_volts_lower_threshold_fatal",
+				"redfish_chassis_fan_rpm_lower_threshold_fatal",
+			},
+			expected: `
+# HELP redfish_chassis_temperature_celsius_upper_threshold_critical threshold above the normal range that is not considered fatal
+# TYPE redfish_chassis_temperature_celsius_upper_threshold_critical gauge
+redfish_chassis_temperature_celsius_upper_threshold_critical{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 90
+`,
+		},
+		{
+			name: "thermal absent sensor",
+			setup: func(s *redfishtest.Server) {
+				update(s, thermalURI, func(r redfishtest.Resource) {
+					member(r, "Temperatures")["Status"] = redfishtest.Resource{"State": "Absent"}
+				})
+			},
+			names: []string{"redfish_chassis_temperature_sensor_state"},
+			absent: []string{
+				"redfish_chassis_temperature_celsius",
+				"redfish_chassis_temperature_celsius_upper_threshold_critical",
+			},
+			expected: `
+# HELP redfish_chassis_temperature_sensor_state state of chassis.temprature_sensor,1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)
+# TYPE redfish_chassis_temperature_sensor_state gauge
+redfish_chassis_temperature_sensor_state{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 7
+`,
+		},
+		{
+			name: "fan rpm",
+			names: []string{
+				"redfish_chassis_fan_rpm",
+				"redfish_chassis_fan_rpm_percentage",
+				"redfish_chassis_fan_rpm_max",
+				"redfish_chassis_fan_rpm_lower_threshold_critical",
+			},
+			expected: `
+# HELP redfish_chassis_fan_rpm RPM of the fan
+# TYPE redfish_chassis_fan_rpm gauge
+redfish_chassis_fan_rpm{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="rpm",resource="fan"} 4200
+# HELP redfish_chassis_fan_rpm_lower_threshold_critical threshold below the normal range that is not considered fatal
+# TYPE redfish_chassis_fan_rpm_lower_threshold_critical gauge
+redfish_chassis_fan_rpm_lower_threshold_critical{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="rpm",resource="fan"} 500
+# HELP redfish_chassis_fan_rpm_max Maximum possible RPM of the fan
+# TYPE redfish_chassis_fan_rpm_max gauge
+redfish_chassis_fan_rpm_max{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="rpm",resource="fan"} 8400
+# HELP redfish_chassis_fan_rpm_percentage Percentage of the fan's RPM compared to the miniumum-maximum RPM
+# TYPE redfish_chassis_fan_rpm_percentage gauge
+redfish_chassis_fan_rpm_percentage{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="rpm",resource="fan"} 50
+`,
+		},
+		{
+			name: "fan percent",
+			setup: func(s *redfishtest.Server) {
+				update(s, thermalURI, func(r redfishtest.Resource) {
+					fan := member(r, "Fans")
+					fan["Reading"] = 25
+					fan["ReadingUnits"] = "Percent"
+					fan["MaxReadingRange"] = 10000
+				})
+			},
+			names: []string{"redfish_chassis_fan_rpm", "redfish_chassis_fan_rpm_percentage"},
+			expected: `
+# HELP redfish_chassis_fan_rpm RPM of the fan
+# TYPE redfish_chassis_fan_rpm gauge
+redfish_chassis_fan_rpm{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="percent",resource="fan"} 2500
+# HELP redfish_chassis_fan_rpm_percentage Percentage of the fan's RPM compared to the miniumum-maximum RPM
+# TYPE redfish_chassis_fan_rpm_percentage gauge
+redfish_chassis_fan_rpm_percentage{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="percent",resource="fan"} 25
+`,
+		},
+		{
+			name: "fan without range",
+			setup: func(s *redfishtest.Server) {
+				update(s, thermalURI, func(r redfishtest.Resource) {
+					delete(member(r, "Fans"), "MaxReadingRange")
+				})
+			},
+			names:  []string{"redfish_chassis_fan_rpm"},
+			absent: []string{"redfish_chassis_fan_rpm_percentage", "redfish_chassis_fan_rpm_max"},
+			expected: `
+# HELP redfish_chassis_fan_rpm RPM of the fan
+# TYPE redfish_chassis_fan_rpm gauge
+redfish_chassis_fan_rpm{chassis_id="1U",fan="Fan 1",fan_id="0",fan_unit="rpm",resource="fan"} 4200
+`,
+		},
+		{
+			name: "power",
+			names: []string{
+				"redfish_chassis_power_average_consumed_watts",
+				"redfish_chassis_power_power_supply_input_watts",
+				"redfish_chassis_power_power_supply_efficiency_percentage",
+				"redfish_chassis_power_voltage_volts",
+				"redfish_chassis_power_voltage_volts_lower_threshold_critical",
+			},
+			expected: `
+# HELP redfish_chassis_power_average_consumed_watts Average power consumed in watts
+# TYPE redfish_chassis_power_average_consumed_watts gauge
+redfish_chassis_power_average_consumed_watts{chassis_id="1U",member_id="0",name="System Power Control",resource="power_control"} 319
+# HELP redfish_chassis_power_power_supply_efficiency_percentage Power supply efficiency percentage
+# TYPE redfish_chassis_power_power_supply_efficiency_percentage gauge
+redfish_chassis_power_power_supply_efficiency_percentage{chassis_id="1U",member_id="0",name="Power Supply Bay 1",resource="power_supply"} 94
+# HELP redfish_chassis_power_power_supply_input_watts Power supply input watts
+# TYPE redfish_chassis_power_power_supply_input_watts gauge
+redfish_chassis_power_power_supply_input_watts{chassis_id="1U",member_id="0",name="Power Supply Bay 1",resource="power_supply"} 340
+# HELP redfish_chassis_power_voltage_volts Voltage of the power supply
+# TYPE redfish_chassis_power_voltage_volts gauge
+redfish_chassis_power_voltage_volts{chassis_id="1U",member_id="0",name="VRM1 Voltage",resource="power_voltage"} 12
+# HELP redfish_chassis_power_voltage_volts_lower_threshold_critical threshold below the normal range that is not considered fatal
+# TYPE redfish_chassis_power_voltage_volts_lower_threshold_critical gauge
+redfish_chassis_power_voltage_volts_lower_threshold_critical{chassis_id="1U",member_id="0",name="VRM1 Voltage",resource="power_voltage"} 11
+`,
+		},
+		{
+			name: "power without metrics",
+			setup: func(s *redfishtest.Server) {
+				update(s, powerURI, func(r redfishtest.Resource) {
+					delete(member(r, "PowerControl"), "PowerMetrics")
+					member(r, "PowerSupplies")["PowerInputWatts"] = nil
+				})
+			},
+			absent: []string{"redfish_chassis_power_average_consumed_watts", "redfish_chassis_power_power_supply_input_watts"},
+		},
+		{
+			name: "network",
+			names: []string{
+				"redfish_chassis_network_adapter_tx_bytes",
+				"redfish_chassis_network_adapter_rx_bytes",
+				"redfish_chassis_network_port_link_status",
+			},
+			expected: `
+# HELP redfish_chassis_network_adapter_rx_bytes Received bytes of the network adapter
+# TYPE redfish_chassis_network_adapter_rx_bytes counter
+redfish_chassis_network_adapter_rx_bytes{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",resource="network_adapter"} 2000
+# HELP redfish_chassis_network_adapter_tx_bytes Transmitted bytes of the network adapter
+# TYPE redfish_chassis_network_adapter_tx_bytes counter
+redfish_chassis_network_adapter_tx_bytes{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",resource="network_adapter"} 1000
+# HELP redfish_chassis_network_port_link_status Link status of the network port
+# TYPE redfish_chassis_network_port_link_status gauge
+redfish_chassis_network_port_link_status{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",network_port="Port 1",network_port_connection_type="Ethernet",network_port_id="1",network_port_physical_number="1",network_port_speed="10000",resource="network_adapter"} 1
+`,
+		},
+		{
+			name: "network null counter",
+			setup: func(s *redfishtest.Server) {
+				update(s, adapterURI, func(r redfishtest.Resource) {
+					r["Metrics"] = redfishtest.Resource{"TXBytes": nil, "RXBytes": 2000}
+				})
+			},
+			names:  []string{"redfish_chassis_network_adapter_rx_bytes"},
+			absent: []string{"redfish_chassis_network_adapter_tx_bytes"},
+			expected: `
+# HELP redfish_chassis_network_adapter_rx_bytes Received bytes of the network adapter
+# TYPE redfish_chassis_network_adapter_rx_bytes counter
+redfish_chassis_network_adapter_rx_bytes{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",resource="network_adapter"} 2000
+`,
+		},
+		{
+			name: "network link down",
+			setup: func(s *redfishtest.Server) {
+				update(s, portURI, func(r redfishtest.Resource) {
+					r["LinkStatus"] = "Down"
+				})
+			},
+			names: []string{"redfish_chassis_network_port_link_status"},
+			expected: `
+# HELP redfish_chassis_network_port_link_status Link status of the network port
+# TYPE redfish_chassis_network_port_link_status gauge
+redfish_chassis_network_port_link_status{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",network_port="Port 1",network_port_connection_type="Ethernet",network_port_id="1",network_port_physical_number="1",network_port_speed="10000",resource="network_adapter"} 0
+`,
+		},
+		{
+			name: "no network adapters",
+			setup: func(s *redfishtest.Server) {
+				update(s, chassisURI, func(r redfishtest.Resource) {
+					delete(r, "NetworkAdapters")
+				})
+			},
+			absent: []string{"redfish_chassis_network_adapter_tx_bytes", "redfish_chassis_network_port_link_status"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t, tc.cfg, tc.setup)
+			h.compare(t, context.Background(), tc.expected, tc.names, tc.absent)
+		})
+	}
+}
+
+const scrapeStatusOK = `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="chassis"} 1
+`
+
+func TestCollectorFaults(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		fault    redfishtest.Fault
+		uri      string
+		timeout  time.Duration
+		names    []string
+		absent   []string
+		expected string
+	}{
+		{
+			name:   "chassis collection error",
+			uri:    "/redfish/v1/Chassis",
+			fault:  redfishtest.Fault{Status: http.StatusInternalServerError},
+			names:  []string{"redfish_collector_scrape_status"},
+			absent: []string{"redfish_chassis_health"},
+			expected: `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="chassis"} 0
+`,
+		},
+		{
+			name:   "thermal error",
+			uri:    thermalURI,
+			fault:  redfishtest.Fault{Status: http.StatusInternalServerError},
+			names:  []string{"redfish_chassis_power_voltage_volts", "redfish_collector_scrape_status"},
+			absent: []string{"redfish_chassis_temperature_celsius", "redfish_chassis_fan_rpm"},
+			expected: scrapeStatusOK + `
+# HELP redfish_chassis_power_voltage_volts Voltage of the power supply
+# TYPE redfish_chassis_power_voltage_volts gauge
+redfish_chassis_power_voltage_volts{chassis_id="1U",member_id="0",name="VRM1 Voltage",resource="power_voltage"} 12
+`,
+		},
+		{
+			name:   "malformed power",
+			uri:    powerURI,
+			fault:  redfishtest.Fault{Malformed: true},
+			names:  []string{"redfish_chassis_temperature_celsius"},
+			absent: []string{"redfish_chassis_power_voltage_volts"},
+			expected: `
+# HELP redfish_chassis_temperature_celsius celcius temperature of the chassis component
+# TYPE redfish_chassis_temperature_celsius gauge
+redfish_chassis_temperature_celsius{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 41
+`,
+		},
+		{
+			name:   "malformed network port",
+			uri:    portURI,
+			fault:  redfishtest.Fault{Malformed: true},
+			names:  []string{"redfish_chassis_network_adapter_tx_bytes"},
+			absent: []string{"redfish_chassis_network_port_link_status"},
+			expected: `
+# HELP redfish_chassis_network_adapter_tx_bytes Transmitted bytes of the network adapter
+# TYPE redfish_chassis_network_adapter_tx_bytes counter
+redfish_chassis_network_adapter_tx_bytes{chassis_id="1U",network_adapter="Network Adapter 1",network_adapter_id="NIC1",resource="network_adapter"} 1000
+`,
+		},
+		{
+			name:    "slow power",
+			uri:     powerURI,
+			fault:   redfishtest.Fault{Latency: time.Minute},
+			timeout: time.Second,
+			names:   []string{"redfish_chassis_temperature_celsius", "redfish_scrape_timeout"},
+			absent:  []string{"redfish_chassis_power_voltage_volts"},
+			expected: `
+# HELP redfish_chassis_temperature_celsius celcius temperature of the chassis component
+# TYPE redfish_chassis_temperature_celsius gauge
+redfish_chassis_temperature_celsius{chassis_id="1U",resource="temperature",sensor="Thermal",sensor_id="0"} 41
+# HELP redfish_scrape_timeout 1 if the scrape deadline was reached before all collectors finished and only the completed metrics were returned
+# TYPE redfish_scrape_timeout gauge
+redfish_scrape_timeout 1
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// A stalled request must not hold the only request slot.
+			cfg := config.Config{Host: config.Host{MaxConcurrentRequests: 4}}
+			h := newHarness(t, cfg, func(s *redfishtest.Server) {
+				s.Fault(tc.uri, tc.fault)
+			})
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			h.compare(t, ctx, tc.expected, tc.names, tc.absent)
+		})
+	}
+}
+
+func TestCollectorSessionExpiry(t *testing.T) {
+	h := newHarness(t, config.Config{}, nil)
+	h.compare(t, context.Background(), scrapeStatusOK, []string{"redfish_collector_scrape_status"}, nil)
+
+	// The first scrape after the BMC dropped the session fails, the next one
+	// logs in again.
+	h.server.ExpireSessions()
+	h.compare(t, context.Background(), `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="chassis"} 0
+`, []string{"redfish_collector_scrape_status"}, nil)
+	h.compare(t, context.Background(), scrapeStatusOK, []string{"redfish_collector_scrape_status"}, nil)
+
+	if got := h.server.SessionCount(); got != 2 {
+		t.Errorf("expected 2 sessions, got %d", got)
+	}
+}
+
+func TestCollectorServiceRoot(t *testing.T) {
+	h := newHarness(t, config.Config{}, nil)
+	for i := 0; i < 3; i++ {
+		h.compare(t, context.Background(), scrapeStatusOK, []string{"redfish_collector_scrape_status"}, nil)
+	}
+
+	// The service root is fetched when connecting, not on every scrape.
+	if got := h.server.Requests(redfishtest.ServiceRoot); got != 1 {
+		t.Errorf("expected the service root to be fetched once, got %d", got)
+	}
+}
+
+func TestCollectorBasicAuth(t *testing.T) {
+	h := newHarness(t, config.Config{Host: config.Host{BasicAuth: true}}, nil)
+	h.server.ExpireSessions()
+	h.compare(t, context.Background(), scrapeStatusOK, []string{"redfish_collector_scrape_status"}, nil)
+
+	if got := h.server.SessionCount(); got != 0 {
+		t.Errorf("expected no sessions with basic auth, got %d", got)
+	}
+}
+
+func TestCollectorTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("")
+	h := newTracedHarness(t, config.Config{}, func(s *redfishtest.Server) {
+		s.Fault(powerURI, redfishtest.Fault{Status: http.StatusInternalServerError})
+	}, tracer)
+
+	ctx, scrape := tracer.Start(context.Background(), "scrape")
+	h.compare(t, ctx, "", nil, nil)
+	scrape.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == scrape.SpanContext().TraceID() {
+			spans[span.Name()] = span
+		}
+	}
+
+	chassis, ok := spans["chassis"]
+	if !ok {
+		t.Fatal("missing span chassis")
+	}
+	if chassis.Parent().SpanID() != scrape.SpanContext().SpanID() {
+		t.Error("span chassis is not a child of the scrape")
+	}
+	for _, name := range []string{
+		"collectBasicMetrics",
+		"collectThermalMetrics",
+		"collectPowerMetrics",
+		"collectNetworkMetrics",
+		"GET /redfish/v1/Chassis",
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("missing span %s", name)
+			continue
+		}
+		if span.Parent().SpanID() != chassis.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of chassis", name)
+		}
+	}
+	for name, parent := range map[string]string{
+		"GET /redfish/v1/Chassis/{id}/Thermal": "collectThermalMetrics",
+		"GET /redfish/v1/Chassis/{id}/Power":   "collectPowerMetrics",
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("missing span %s", name)
+			continue
+		}
+		if span.Parent().SpanID() != spans[parent].SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of %s", name, parent)
+		}
+	}
+
+	for name, expected := range map[string]codes.Code{
+		"GET /redfish/v1/Chassis/{id}/Thermal": codes.Unset,
+		"GET /redfish/v1/Chassis/{id}/Power":   codes.Error,
+	} {
+		span, ok := spans[name]
+		if !ok {
+			continue
+		}
+		if got := span.Status().Code; got != expected {
+			t.Errorf("span %s: expected status %s, got %s", name, expected, got)
+		}
+	}
+
+	thermal, ok := spans["GET /redfish/v1/Chassis/{id}/Thermal"]
+	if !ok {
+		return
+	}
+	attributes := attribute.NewSet(thermal.Attributes()...)
+	if got, _ := attributes.Value("url.path"); got.AsString() != thermalURI {
+		t.Errorf("expected url.path %s, got %q", thermalURI, got.AsString())
+	}
+	if got, _ := attributes.Value("http.response.status_code"); got.AsInt64() != http.StatusOK {
+		t.Errorf("expected status code 200, got %d", got.AsInt64())
+	}
+	if got, _ := attributes.Value("http.response.body.size"); got.AsInt64() <= 0 {
+		t.Errorf("expected the body size, got %d", got.AsInt64())
+	}
+}
@@ -9,18 +9,12 @@ import (
 )
 
 const (
-	fanStateMetric                        = "fan_state"
-	fanHealthMetric                       = "fan_health"
-	fanRPMMetric                          = "fan_rpm"
-	fanRPMPercentageMetric                = "fan_rpm_percentage"
-	fanRPMMinMetric                       = "fan_rpm_min"
-	fanRPMMaxMetric                       = "fan_rpm_max"
-	fanRPMLowerThresholdNonCriticalMetric = "fan_rpm_lower_threshold_non_critical"
-	fanRPMLowerThresholdCriticalMetric    = "fan_rpm_lower_threshold_critical"
-	fanRPMLowerThresholdFatalMetric       = "fan_rpm_lower_threshold_fatal"
-	fanRPMUpperThresholdNonCriticalMetric = "fan_rpm_upper_threshold_non_critical"
-	fanRPMUpperThresholdCriticalMetric    = "fan_rpm_upper_threshold_critical"
-	fanRPMUpperThresholdFatalMetric       = "fan_rpm_upper_threshold_fatal"
+	fanStateMetric         = "fan_state"
+	fanHealthMetric        = "fan_health"
+	fanRPMMetric           = "fan_rpm"
+	fanRPMPercentageMetric = "fan_rpm_percentage"
+	fanRPMMinMetric        = "fan_rpm_min"
+	fanRPMMaxMetric        = "fan_rpm_max"
 )
 
 var (
@@ -28,18 +22,17 @@ var (
 )
 
 func fanMetrics() map[string]*prometheus.Desc {
-	return map[string]*prometheus.Desc{
-		fanStateMetric: prometheus.NewDesc(
+	metrics := collectors.ThresholdMetrics(subsystem, fanRPMMetric, append(labels, fanLabels...))
+	for name, desc := range map[string]*prometheus.Desc{
+		fanStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, fanStateMetric),
-			collectors.StateHelp("chassis.fan"),
+			"chassis.fan",
 			append(labels, fanLabels...),
-			nil,
 		),
-		fanHealthMetric: prometheus.NewDesc(
+		fanHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, fanHealthMetric),
-			collectors.HealthHelp("chassis.fan"),
+			"chassis.fan",
 			append(labels, fanLabels...),
-			nil,
 		),
 		fanRPMMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMMetric),
@@ -65,43 +58,10 @@ func fanMetrics() map[string]*prometheus.Desc {
 			append(labels, fanLabels...),
 			nil,
 		),
-		fanRPMLowerThresholdNonCriticalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMLowerThresholdNonCriticalMetric),
-			"threshold below the normal range that is not considered critical",
-			append(labels, fanLabels...),
-			nil,
-		),
-		fanRPMLowerThresholdCriticalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMLowerThresholdCriticalMetric),
-			"threshold below the normal range that is not considered fatal",
-			append(labels, fanLabels...),
-			nil,
-		),
-		fanRPMLowerThresholdFatalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMLowerThresholdFatalMetric),
-			"threshold below the normal range that is considered fatal",
-			append(labels, fanLabels...),
-			nil,
-		),
-		fanRPMUpperThresholdNonCriticalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMUpperThresholdNonCriticalMetric),
-			"threshold above the normal range that is not considered critical",
-			append(labels, fanLabels...),
-			nil,
-		),
-		fanRPMUpperThresholdCriticalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMUpperThresholdCriticalMetric),
-			"threshold above the normal range that is not considered fatal",
-			append(labels, fanLabels...),
-			nil,
-		),
-		fanRPMUpperThresholdFatalMetric: prometheus.NewDesc(
-			prometheus.BuildFQName(collectors.Namespace, subsystem, fanRPMUpperThresholdFatalMetric),
-			"threshold above the normal range that is considered fatal",
-			append(labels, fanLabels...),
-			nil,
-		),
+	} {
+		metrics[name] = desc
 	}
+	return metrics
 }
 func (c *Collector) collectFanMetrics(ch chan<- prometheus.Metric, chassis *redfish.Chassis, thermal *redfish.Thermal) {
 	c.logger.Debug("Collecting fan metrics")
@@ -114,26 +74,35 @@ func (c *Collector) collectFanMetrics(ch chan<- prometheus.Metric, chassis *redf
 		if state, ok := collectors.StateToFloat(fan.Status.State); ok {
 			ch <- prometheus.MustNewConstMetric(c.metrics[fanStateMetric], prometheus.GaugeValue, state, labelValues...)
 		}
-		rpm := float64(fan.Reading)
-		percentage := float64(fan.Reading)
-		if fan.ReadingUnits == redfish.PercentReadingUnits {
-			rpm = rpm * float64(fan.MaxReadingRange) / 100
-		} else {
-			percentage = (percentage / float64(fan.MaxReadingRange)) * 100
+		if c.skipReadings(fan.Status.State) {
+			continue
 		}
 
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMMetric], prometheus.GaugeValue, float64(rpm), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMPercentageMetric], prometheus.GaugeValue, float64(percentage), labelValues...)
-
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMMinMetric], prometheus.GaugeValue, float64(fan.MinReadingRange), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMMaxMetric], prometheus.GaugeValue, float64(fan.MaxReadingRange), labelValues...)
-
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMLowerThresholdNonCriticalMetric], prometheus.GaugeValue, float64(fan.LowerThresholdNonCritical), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMLowerThresholdCriticalMetric], prometheus.GaugeValue, float64(fan.LowerThresholdCritical), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMLowerThresholdFatalMetric], prometheus.GaugeValue, float64(fan.LowerThresholdFatal), labelValues...)
+		// Fans report either RPM or a percentage of MaxReadingRange, the
+		// other value can only be derived if the range is known.
+		maxRange, _ := fan.Readings.Get("MaxReadingRange")
+		if reading, ok := c.reading(fan.Readings, "Reading"); ok {
+			if fan.ReadingUnits == redfish.PercentReadingUnits {
+				ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMPercentageMetric], prometheus.GaugeValue, reading, labelValues...)
+				if maxRange > 0 {
+					ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMMetric], prometheus.GaugeValue, reading*maxRange/100, labelValues...)
+				}
+			} else {
+				ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMMetric], prometheus.GaugeValue, reading, labelValues...)
+				if maxRange > 0 {
+					ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMPercentageMetric], prometheus.GaugeValue, reading/maxRange*100, labelValues...)
+				}
+			}
+		}
 
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMUpperThresholdNonCriticalMetric], prometheus.GaugeValue, float64(fan.UpperThresholdNonCritical), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMUpperThresholdCriticalMetric], prometheus.GaugeValue, float64(fan.UpperThresholdCritical), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[fanRPMUpperThresholdFatalMetric], prometheus.GaugeValue, float64(fan.UpperThresholdFatal), labelValues...)
+		for metric, property := range map[string]string{
+			fanRPMMinMetric: "MinReadingRange",
+			fanRPMMaxMetric: "MaxReadingRange",
+		} {
+			if value, ok := c.reading(fan.Readings, property); ok {
+				ch <- prometheus.MustNewConstMetric(c.metrics[metric], prometheus.GaugeValue, value, labelValues...)
+			}
+		}
+		c.collectThresholds(ch, fanRPMMetric, fan.Readings, labelValues)
 	}
 }
@@ -28,17 +28,15 @@ var (
 
 func networkMetrics() map[string]*prometheus.Desc {
 	return map[string]*prometheus.Desc{
-		networkAdapterStateMetric: prometheus.NewDesc(
+		networkAdapterStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkAdapterStateMetric),
-			collectors.StateHelp("chassis.network_adapter"),
+			"chassis.network_adapter",
 			append(labels, networkAdapterLabels...),
-			nil,
 		),
-		networkAdapterHealthMetric: prometheus.NewDesc(
+		networkAdapterHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkAdapterHealthMetric),
-			collectors.HealthHelp("chassis.network_adapter"),
+			"chassis.network_adapter",
 			append(labels, networkAdapterLabels...),
-			nil,
 		),
 		networkAdapterTXBytesMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkAdapterTXBytesMetric),
@@ -52,17 +50,15 @@ func networkMetrics() map[string]*prometheus.Desc {
 			append(labels, networkAdapterLabels...),
 			nil,
 		),
-		networkPortStateMetric: prometheus.NewDesc(
+		networkPortStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkPortStateMetric),
-			collectors.StateHelp("chassis.network_port"),
+			"chassis.network_port",
 			append(append(labels, networkAdapterLabels...), networkPortLabels...),
-			nil,
 		),
-		networkPortHealthMetric: prometheus.NewDesc(
+		networkPortHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkPortHealthMetric),
-			collectors.HealthHelp("chassis.network_port"),
+			"chassis.network_port",
 			append(append(labels, networkAdapterLabels...), networkPortLabels...),
-			nil,
 		),
 		networkPortLinkStatusMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, networkPortLinkStatusMetric),
@@ -75,11 +71,11 @@ func networkMetrics() map[string]*prometheus.Desc {
 
 func (c *Collector) collectNetworkMetrics(ch chan<- prometheus.Metric, chassis *redfish.Chassis) {
 	c.logger.Debug("Collecting network metrics")
-	adapters, err := chassis.NetworkAdapters()
+	adapters, err := redfish.GetNetworkAdapters(chassis)
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("Failed to get network adapter information for chassis %s", chassis.ID), zap.Error(err))
 		return
-	} else if adapters == nil {
+	} else if len(adapters) == 0 {
 		c.logger.Warn(fmt.Sprintf("No network adapter information for chassis %s", chassis.ID))
 		return
 	}
@@ -92,8 +88,12 @@ func (c *Collector) collectNetworkMetrics(ch chan<- prometheus.Metric, chassis *
 		if state, ok := collectors.StateToFloat(adapter.Status.State); ok {
 			ch <- prometheus.MustNewConstMetric(c.metrics[networkAdapterStateMetric], prometheus.GaugeValue, state, labels...)
 		}
-		ch <- prometheus.MustNewConstMetric(c.metrics[networkAdapterTXBytesMetric], prometheus.CounterValue, float64(adapter.Metrics.TXBytes), labels...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[networkAdapterRXBytesMetric], prometheus.CounterValue, float64(adapter.Metrics.RXBytes), labels...)
+		if txBytes, ok := c.reading(adapter.Readings, "Metrics.TXBytes"); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[networkAdapterTXBytesMetric], prometheus.CounterValue, txBytes, labels...)
+		}
+		if rxBytes, ok := c.reading(adapter.Readings, "Metrics.RXBytes"); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[networkAdapterRXBytesMetric], prometheus.CounterValue, rxBytes, labels...)
+		}
 
 		ports, err := adapter.NetworkPorts()
 		if err != nil {
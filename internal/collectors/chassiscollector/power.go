@@ -28,18 +28,17 @@ var (
 )
 
 func powerMetrics() map[string]*prometheus.Desc {
-	return map[string]*prometheus.Desc{
-		powerVoltageStateMetric: prometheus.NewDesc(
+	metrics := collectors.ThresholdMetrics(subsystem, powerVoltageVoltsMetric, append(labels, powerLabels...))
+	for name, desc := range map[string]*prometheus.Desc{
+		powerVoltageStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerVoltageStateMetric),
-			collectors.StateHelp("chassis.power_voltage"),
+			"chassis.power_voltage",
 			append(labels, powerLabels...),
-			nil,
 		),
-		powerVoltageHealthMetric: prometheus.NewDesc(
+		powerVoltageHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerVoltageHealthMetric),
-			collectors.HealthHelp("chassis.power_voltage"),
+			"chassis.power_voltage",
 			append(labels, powerLabels...),
-			nil,
 		),
 		powerVoltageVoltsMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerVoltageVoltsMetric),
@@ -53,17 +52,15 @@ func powerMetrics() map[string]*prometheus.Desc {
 			append(labels, powerLabels...),
 			nil,
 		),
-		powerPowerSupplyStateMetric: prometheus.NewDesc(
+		powerPowerSupplyStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerPowerSupplyStateMetric),
-			collectors.StateHelp("chassis.power_supply"),
+			"chassis.power_supply",
 			append(labels, powerLabels...),
-			nil,
 		),
-		powerPowerSupplyHealthMetric: prometheus.NewDesc(
+		powerPowerSupplyHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerPowerSupplyHealthMetric),
-			collectors.HealthHelp("chassis.power_supply"),
+			"chassis.power_supply",
 			append(labels, powerLabels...),
-			nil,
 		),
 		powerPowerSupplyInputWattsMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, powerPowerSupplyInputWattsMetric),
@@ -95,12 +92,15 @@ func powerMetrics() map[string]*prometheus.Desc {
 			append(labels, powerLabels...),
 			nil,
 		),
+	} {
+		metrics[name] = desc
 	}
+	return metrics
 }
 
 func (c *Collector) collectPowerMetrics(ch chan<- prometheus.Metric, chassis *redfish.Chassis) {
 	c.logger.Debug("Collecting power metrics")
-	power, err := chassis.Power()
+	power, err := redfish.GetPower(chassis)
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("Failed to get power information for chassis %s", chassis.ID), zap.Error(err))
 		return
@@ -117,13 +117,21 @@ func (c *Collector) collectPowerMetrics(ch chan<- prometheus.Metric, chassis *re
 		if health, ok := collectors.HealthToFloat(voltage.Status.Health); ok {
 			ch <- prometheus.MustNewConstMetric(c.metrics[powerVoltageHealthMetric], prometheus.GaugeValue, health, labelValues...)
 		}
+		if c.skipReadings(voltage.Status.State) {
+			continue
+		}
 
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerVoltageVoltsMetric], prometheus.GaugeValue, float64(voltage.ReadingVolts), labelValues...)
+		if volts, ok := c.reading(voltage.Readings, "ReadingVolts"); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[powerVoltageVoltsMetric], prometheus.GaugeValue, volts, labelValues...)
+		}
+		c.collectThresholds(ch, powerVoltageVoltsMetric, voltage.Readings, labelValues)
 	}
 
 	for _, powerControl := range power.PowerControl {
 		labelValues := []string{"power_control", chassis.ID, powerControl.Name, powerControl.MemberID}
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerAverageConsumedWattsMetric], prometheus.GaugeValue, float64(powerControl.PowerMetrics.AverageConsumedWatts), labelValues...)
+		if watts, ok := c.reading(powerControl.Readings, "PowerMetrics.AverageConsumedWatts"); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[powerAverageConsumedWattsMetric], prometheus.GaugeValue, watts, labelValues...)
+		}
 	}
 
 	for _, powerSupply := range power.PowerSupplies {
@@ -134,10 +142,20 @@ func (c *Collector) collectPowerMetrics(ch chan<- prometheus.Metric, chassis *re
 		if health, ok := collectors.HealthToFloat(powerSupply.Status.Health); ok {
 			ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyHealthMetric], prometheus.GaugeValue, health, labelValues...)
 		}
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyInputWattsMetric], prometheus.GaugeValue, float64(powerSupply.PowerInputWatts), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyOutputWattsMetric], prometheus.GaugeValue, float64(powerSupply.PowerOutputWatts), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyEfficiencyPercentageMetric], prometheus.GaugeValue, float64(powerSupply.EfficiencyPercent), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyPowerCapacityWattsMetric], prometheus.GaugeValue, float64(powerSupply.PowerCapacityWatts), labelValues...)
-		ch <- prometheus.MustNewConstMetric(c.metrics[powerPowerSupplyLastPowerOutputWattsMetric], prometheus.GaugeValue, float64(powerSupply.LastPowerOutputWatts), labelValues...)
+		if c.skipReadings(powerSupply.Status.State) {
+			continue
+		}
+
+		for metric, property := range map[string]string{
+			powerPowerSupplyInputWattsMetric:           "PowerInputWatts",
+			powerPowerSupplyOutputWattsMetric:          "PowerOutputWatts",
+			powerPowerSupplyEfficiencyPercentageMetric: "EfficiencyPercent",
+			powerPowerSupplyPowerCapacityWattsMetric:   "PowerCapacityWatts",
+			powerPowerSupplyLastPowerOutputWattsMetric: "LastPowerOutputWatts",
+		} {
+			if value, ok := c.reading(powerSupply.Readings, property); ok {
+				ch <- prometheus.MustNewConstMetric(c.metrics[metric], prometheus.GaugeValue, value, labelValues...)
+			}
+		}
 	}
 }
@@ -20,18 +20,17 @@ var (
 )
 
 func thermalChassisMetrics() map[string]*prometheus.Desc {
-	return map[string]*prometheus.Desc{
-		tempSensorStateMetric: prometheus.NewDesc(
+	metrics := collectors.ThresholdMetrics(subsystem, tempSensorTempMetric, append(labels, tempSensorLabels...))
+	for name, desc := range map[string]*prometheus.Desc{
+		tempSensorStateMetric: collectors.NewStateDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, tempSensorStateMetric),
-			collectors.StateHelp("chassis.temprature_sensor"),
+			"chassis.temprature_sensor",
 			append(labels, tempSensorLabels...),
-			nil,
 		),
-		tempSensorHealthMetric: prometheus.NewDesc(
+		tempSensorHealthMetric: collectors.NewHealthDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, tempSensorHealthMetric),
-			collectors.HealthHelp("chassis.temprature_sensor"),
+			"chassis.temprature_sensor",
 			append(labels, tempSensorLabels...),
-			nil,
 		),
 		tempSensorTempMetric: prometheus.NewDesc(
 			prometheus.BuildFQName(collectors.Namespace, subsystem, tempSensorTempMetric),
@@ -39,11 +38,14 @@ func thermalChassisMetrics() map[string]*prometheus.Desc {
 			append(labels, tempSensorLabels...),
 			nil,
 		),
+	} {
+		metrics[name] = desc
 	}
+	return metrics
 }
 
 func (c *Collector) collectThermalMetrics(ch chan<- prometheus.Metric, chassis *redfish.Chassis) {
-	thermal, err := chassis.Thermal()
+	thermal, err := redfish.GetThermal(chassis)
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("Failed to get thermal information for chassis %s", chassis.ID), zap.Error(err))
 		return
@@ -61,7 +63,13 @@ func (c *Collector) collectThermalMetrics(ch chan<- prometheus.Metric, chassis *
 		if state, ok := collectors.StateToFloat(tempSensor.Status.State); ok {
 			ch <- prometheus.MustNewConstMetric(c.metrics[tempSensorStateMetric], prometheus.GaugeValue, state, labelValues...)
 		}
-		ch <- prometheus.MustNewConstMetric(c.metrics[tempSensorTempMetric], prometheus.GaugeValue, float64(tempSensor.ReadingCelsius), labelValues...)
+		if c.skipReadings(tempSensor.Status.State) {
+			continue
+		}
+		if celsius, ok := c.reading(tempSensor.Readings, "ReadingCelsius"); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[tempSensorTempMetric], prometheus.GaugeValue, celsius, labelValues...)
+		}
+		c.collectThresholds(ch, tempSensorTempMetric, tempSensor.Readings, labelValues)
 	}
 	c.collectFanMetrics(ch, chassis, thermal)
 }
@@ -0,0 +1,299 @@
+package customcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	// uriLabel is added to every custom metric so series from different
+	// resources matched by a wildcard URI never collide.
+	uriLabel = "uri"
+)
+
+// metric is a compiled config.CustomMetric.
+type metric struct {
+	name       string
+	desc       *prometheus.Desc
+	valueType  prometheus.ValueType
+	uri        string
+	items      path
+	value      path
+	labelNames []string
+	labels     []path
+	valueMap   map[string]float64
+}
+
+// Collector exports metrics declared in the customMetrics section of the
+// config by walking the configured Redfish URIs and extracting values with
+// JSONPath-style expressions.
+type Collector struct {
+	logger       *log.Logger
+	redfish      *redfish.Client
+	config       []config.CustomMetric
+	metrics      []*metric
+	scrapeStatus *collectors.ScrapeStatus
+	tracer       trace.Tracer
+}
+
+func New(logger *log.Logger, client *redfish.Client, cfg config.Config, scrapeStatus *collectors.ScrapeStatus, tracer trace.Tracer) *Collector {
+	return &Collector{
+		logger:       logger,
+		redfish:      client,
+		config:       cfg.CustomMetrics,
+		scrapeStatus: scrapeStatus,
+		tracer:       tracer,
+	}
+}
+
+func Register(collector *Collector, registry *collectors.Registry, lc fx.Lifecycle) {
+	if len(collector.config) == 0 {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			for _, cfg := range collector.config {
+				m, err := compile(cfg)
+				if err != nil {
+					return fmt.Errorf("custom metric %s: %w", cfg.Name, err)
+				}
+				collector.metrics = append(collector.metrics, m)
+			}
+
+			return registry.Register(collector)
+		},
+	})
+}
+
+// Validate reports whether cfg can be compiled into a metric.
+func Validate(cfg config.CustomMetric) error {
+	_, err := compile(cfg)
+	return err
+}
+
+func compile(cfg config.CustomMetric) (*metric, error) {
+	m := &metric{
+		name:      cfg.Name,
+		uri:       cfg.URI,
+		valueType: prometheus.GaugeValue,
+		valueMap:  make(map[string]float64, len(cfg.ValueMap)),
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case "", "gauge":
+	case "counter":
+		m.valueType = prometheus.CounterValue
+	default:
+		return nil, fmt.Errorf("unsupported type %q", cfg.Type)
+	}
+
+	if cfg.URI == "" {
+		return nil, fmt.Errorf("uri is required")
+	}
+
+	var err error
+	if m.items, err = parsePath(cfg.Items); err != nil {
+		return nil, fmt.Errorf("items: %w", err)
+	}
+	if m.value, err = parsePath(cfg.Value); err != nil {
+		return nil, fmt.Errorf("value: %w", err)
+	}
+
+	seen := map[string]bool{uriLabel: true}
+	for _, label := range cfg.Labels {
+		if !model.LabelName(label.Name).IsValidLegacy() {
+			return nil, fmt.Errorf("invalid label name %q", label.Name)
+		}
+		if seen[label.Name] {
+			return nil, fmt.Errorf("duplicate label %s", label.Name)
+		}
+		seen[label.Name] = true
+
+		labelPath, err := parsePath(label.Path)
+		if err != nil {
+			return nil, fmt.Errorf("label %s: %w", label.Name, err)
+		}
+		m.labelNames = append(m.labelNames, label.Name)
+		m.labels = append(m.labels, labelPath)
+	}
+
+	// Viper lower cases map keys, so the lookup in toFloat is done on the
+	// lower cased value as well.
+	for key, value := range cfg.ValueMap {
+		m.valueMap[strings.ToLower(key)] = value
+	}
+
+	help := cfg.Help
+	if help == "" {
+		help = fmt.Sprintf("custom metric from %s", cfg.URI)
+	}
+	m.desc = prometheus.NewDesc(
+		prometheus.BuildFQName(collectors.Namespace, "", cfg.Name),
+		help,
+		append([]string{uriLabel}, m.labelNames...),
+		nil,
+	)
+
+	return m, nil
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *Collector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Debug("Collecting custom metrics")
+	ctx, span := c.tracer.Start(ctx, "custom")
+	defer span.End()
+
+	client, err := c.redfish.WithContext(ctx)
+	if err != nil {
+		c.logger.Error("Failed to connect to redfish service", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("custom").Set(float64(0))
+		return
+	}
+
+	resources := newResourceCache(client)
+	failed := atomic.Bool{}
+	wg := sync.WaitGroup{}
+	for _, m := range c.metrics {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !c.collectMetric(ch, resources, m) {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.logger.Debug("Finished collecting custom metrics")
+	if failed.Load() {
+		c.scrapeStatus.WithLabelValues("custom").Set(float64(0))
+		return
+	}
+	c.scrapeStatus.WithLabelValues("custom").Set(float64(1))
+}
+
+// collectMetric exports the series of m and reports whether all of its
+// resources could be read.
+func (c *Collector) collectMetric(ch chan<- prometheus.Metric, resources *resourceCache, m *metric) bool {
+	uris, err := resources.expand(m.uri)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to expand custom metric uri %s", m.uri), zap.Error(err))
+		return false
+	}
+
+	complete := true
+	// Items the labels do not tell apart would produce duplicate series,
+	// which fail the whole scrape, so only the first of them is exported.
+	seen := make(map[string]bool)
+	duplicates := 0
+	for _, uri := range uris {
+		resource, err := resources.get(uri)
+		if err != nil {
+			c.logger.Error(fmt.Sprintf("Failed to get custom metric resource %s", uri), zap.Error(err))
+			complete = false
+			continue
+		}
+
+		for _, item := range m.items.eval(resource) {
+			for _, raw := range m.value.eval(root(m.value, resource, item)) {
+				value, ok := m.toFloat(raw)
+				if !ok {
+					continue
+				}
+
+				labelValues := []string{uri}
+				for _, labelPath := range m.labels {
+					labelValues = append(labelValues, labelValue(labelPath.eval(root(labelPath, resource, item))))
+				}
+				key := strings.Join(labelValues, "\xff")
+				if seen[key] {
+					duplicates++
+					continue
+				}
+				seen[key] = true
+				ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, value, labelValues...)
+			}
+		}
+	}
+	if duplicates > 0 {
+		c.logger.Warn(fmt.Sprintf("Skipped series of custom metric %s with duplicate labels, add labels that tell its items apart", m.name),
+			zap.Int("duplicates", duplicates))
+	}
+
+	return complete
+}
+
+// root returns what p is evaluated against: the resource for absolute
+// paths, the item otherwise.
+func root(p path, resource, item interface{}) interface{} {
+	if p.absolute {
+		return resource
+	}
+	return item
+}
+
+func (m *metric) toFloat(raw interface{}) (float64, bool) {
+	switch value := raw.(type) {
+	case float64:
+		return value, true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if mapped, ok := m.valueMap[strings.ToLower(value)]; ok {
+			return mapped, true
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func labelValue(values []interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch value := values[0].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case nil:
+		return ""
+	default:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+}
@@ -0,0 +1,282 @@
+package customcollector_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/customcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const powerURI = "/redfish/v1/Chassis/1U/Power"
+
+// newGatherer returns a gatherer scraping metrics from the default tree
+// modified by setup.
+func newGatherer(t *testing.T, metrics []config.CustomMetric, setup func(*redfishtest.Server)) prometheus.Gatherer {
+	t.Helper()
+
+	server := redfishtest.NewServer(redfishtest.DefaultTree())
+	t.Cleanup(server.Close)
+	if setup != nil {
+		setup(server)
+	}
+
+	cfg := config.Config{
+		Host: config.Host{
+			Endpoint: server.URL,
+			Username: server.Username,
+			Password: server.Password,
+		},
+		CustomMetrics: metrics,
+	}
+	tracer := noop.NewTracerProvider().Tracer("")
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := collectors.NewRegistry()
+	scrapeStatus := collectors.NewScrapeStatus()
+	lc := fxtest.NewLifecycle(t)
+	collectors.RegisterScrapeStatus(scrapeStatus, registry, lc)
+	customcollector.Register(customcollector.New(logger, client, cfg, scrapeStatus, tracer), registry, lc)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return registry.GatherContext(context.Background())
+	})
+}
+
+func scrapeStatus(value int) string {
+	return fmt.Sprintf(`
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="custom"} %d
+`, value)
+}
+
+// addPowerControl adds a second power control to the chassis.
+func addPowerControl(s *redfishtest.Server) {
+	power := redfishtest.DefaultTree()[powerURI]
+	power["PowerControl"] = append(power["PowerControl"].([]interface{}), redfishtest.Resource{
+		"MemberId":     "1",
+		"Name":         "Secondary Power Control",
+		"PowerMetrics": redfishtest.Resource{"AverageConsumedWatts": 120},
+	})
+	s.Set(powerURI, power)
+}
+
+func TestCollector(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		metric   config.CustomMetric
+		setup    func(*redfishtest.Server)
+		absent   bool
+		expected string
+	}{
+		{
+			name: "items",
+			metric: config.CustomMetric{
+				Name:  "power_consumed_watts",
+				Help:  "consumed power",
+				URI:   "/redfish/v1/Chassis/*/Power",
+				Items: "PowerControl[*]",
+				Value: "PowerMetrics.AverageConsumedWatts",
+				Labels: []config.CustomLabel{
+					{Name: "memberId", Path: "MemberId"},
+					{Name: "power", Path: "$.Id"},
+				},
+			},
+			setup: addPowerControl,
+			expected: `
+# HELP redfish_power_consumed_watts consumed power
+# TYPE redfish_power_consumed_watts gauge
+redfish_power_consumed_watts{memberId="0",power="Power",uri="/redfish/v1/Chassis/1U/Power"} 319
+redfish_power_consumed_watts{memberId="1",power="Power",uri="/redfish/v1/Chassis/1U/Power"} 120
+`,
+		},
+		{
+			name: "absolute value with items",
+			metric: config.CustomMetric{
+				Name:   "voltage_volts",
+				Help:   "voltage",
+				URI:    powerURI,
+				Items:  "PowerControl[*]",
+				Value:  "$.Voltages[0].ReadingVolts",
+				Labels: []config.CustomLabel{{Name: "member_id", Path: "MemberId"}},
+			},
+			setup: addPowerControl,
+			expected: `
+# HELP redfish_voltage_volts voltage
+# TYPE redfish_voltage_volts gauge
+redfish_voltage_volts{member_id="0",uri="/redfish/v1/Chassis/1U/Power"} 12
+redfish_voltage_volts{member_id="1",uri="/redfish/v1/Chassis/1U/Power"} 12
+`,
+		},
+		{
+			name: "duplicate series",
+			metric: config.CustomMetric{
+				Name:  "power_consumed_watts",
+				Help:  "consumed power",
+				Type:  "counter",
+				URI:   powerURI,
+				Items: "PowerControl[*]",
+				Value: "PowerMetrics.AverageConsumedWatts",
+			},
+			setup: addPowerControl,
+			expected: `
+# HELP redfish_power_consumed_watts consumed power
+# TYPE redfish_power_consumed_watts counter
+redfish_power_consumed_watts{uri="/redfish/v1/Chassis/1U/Power"} 319
+`,
+		},
+		{
+			name: "value map",
+			metric: config.CustomMetric{
+				Name:     "voltage_health",
+				Help:     "voltage health",
+				URI:      powerURI,
+				Items:    "Voltages[*]",
+				Value:    "Status.Health",
+				Labels:   []config.CustomLabel{{Name: "name", Path: "Name"}},
+				ValueMap: map[string]float64{"ok": 1, "warning": 2},
+			},
+			expected: `
+# HELP redfish_voltage_health voltage health
+# TYPE redfish_voltage_health gauge
+redfish_voltage_health{name="VRM1 Voltage",uri="/redfish/v1/Chassis/1U/Power"} 1
+`,
+		},
+		{
+			name: "unconvertible value",
+			metric: config.CustomMetric{
+				Name:  "chassis_name",
+				Help:  "name",
+				URI:   "/redfish/v1/Chassis/1U",
+				Value: "Name",
+			},
+			absent: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gatherer := newGatherer(t, []config.CustomMetric{tc.metric}, tc.setup)
+			names := []string{"redfish_collector_scrape_status"}
+			if !tc.absent {
+				names = append(names, "redfish_"+tc.metric.Name)
+			}
+			expected := tc.expected + scrapeStatus(1)
+			if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), names...); err != nil {
+				t.Error(err)
+			}
+			if tc.absent {
+				if count, err := testutil.GatherAndCount(gatherer, "redfish_"+tc.metric.Name); err != nil || count != 0 {
+					t.Errorf("expected no series, got %d: %v", count, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectorFaults(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		uri  string
+	}{
+		{name: "collection", uri: "/redfish/v1/Chassis"},
+		{name: "resource", uri: powerURI},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := []config.CustomMetric{
+				{Name: "power_consumed_watts", URI: "/redfish/v1/Chassis/*/Power", Value: "PowerControl[0].PowerMetrics.AverageConsumedWatts"},
+				{Name: "chassis_health", URI: "/redfish/v1/Chassis/1U", Value: "Status.Health", ValueMap: map[string]float64{"ok": 1}},
+			}
+			gatherer := newGatherer(t, metrics, func(s *redfishtest.Server) {
+				s.Fault(tc.uri, redfishtest.Fault{Status: http.StatusInternalServerError})
+			})
+			// The metric that could be read is still exported.
+			expected := `
+# HELP redfish_chassis_health custom metric from /redfish/v1/Chassis/1U
+# TYPE redfish_chassis_health gauge
+redfish_chassis_health{uri="/redfish/v1/Chassis/1U"} 1
+` + scrapeStatus(0)
+			if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), "redfish_chassis_health", "redfish_collector_scrape_status"); err != nil {
+				t.Error(err)
+			}
+			if count, err := testutil.GatherAndCount(gatherer, "redfish_power_consumed_watts"); err != nil || count != 0 {
+				t.Errorf("expected no series, got %d: %v", count, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := config.CustomMetric{Name: "chassis_health", URI: "/redfish/v1/Chassis/*", Value: "Status.Health"}
+	for _, tc := range []struct {
+		name   string
+		modify func(*config.CustomMetric)
+		err    string
+	}{
+		{name: "valid", modify: func(*config.CustomMetric) {}},
+		{name: "counter", modify: func(m *config.CustomMetric) { m.Type = "Counter" }},
+		{name: "type", modify: func(m *config.CustomMetric) { m.Type = "histogram" }, err: `unsupported type "histogram"`},
+		{name: "uri", modify: func(m *config.CustomMetric) { m.URI = "" }, err: "uri is required"},
+		{name: "items", modify: func(m *config.CustomMetric) { m.Items = "Voltages[" }, err: "items: unterminated [ in path"},
+		{name: "value", modify: func(m *config.CustomMetric) { m.Value = "Voltages[x]" }, err: `value: invalid index "x" in path`},
+		{
+			name:   "label path",
+			modify: func(m *config.CustomMetric) { m.Labels = []config.CustomLabel{{Name: "id", Path: "Id["}} },
+			err:    "label id: unterminated [ in path",
+		},
+		{
+			name:   "label name",
+			modify: func(m *config.CustomMetric) { m.Labels = []config.CustomLabel{{Name: "chassis-id", Path: "Id"}} },
+			err:    `invalid label name "chassis-id"`,
+		},
+		{
+			name: "duplicate label",
+			modify: func(m *config.CustomMetric) {
+				m.Labels = []config.CustomLabel{{Name: "id", Path: "Id"}, {Name: "id", Path: "Name"}}
+			},
+			err: "duplicate label id",
+		},
+		{
+			name:   "uri label",
+			modify: func(m *config.CustomMetric) { m.Labels = []config.CustomLabel{{Name: "uri", Path: "Id"}} },
+			err:    "duplicate label uri",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metric := valid
+			tc.modify(&metric)
+			err := customcollector.Validate(metric)
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package customcollector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// path is a parsed JSONPath-style expression. It supports the subset that is
+// useful for Redfish resources: dotted keys, bracketed keys for names that
+// contain dots (['@odata.id']), array indexes ([0]) and wildcards ([*]) that
+// match every element of an array or every value of an object.
+//
+// Expressions starting with $ are absolute, i.e. evaluated against the
+// resource rather than the selected item.
+type path struct {
+	absolute bool
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parsePath(expr string) (path, error) {
+	p := path{}
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") {
+		p.absolute = true
+		expr = expr[1:]
+	}
+
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated [ in path")
+			}
+			inner := expr[1:end]
+			expr = expr[end+1:]
+
+			switch {
+			case inner == "*":
+				p.segments = append(p.segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.segments = append(p.segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return p, fmt.Errorf("invalid index %q in path", inner)
+				}
+				p.segments = append(p.segments, pathSegment{index: index, isIndex: true})
+			}
+		default:
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			p.segments = append(p.segments, pathSegment{key: expr[:end]})
+			expr = expr[end:]
+		}
+	}
+
+	return p, nil
+}
+
+// eval returns every value the path matches in v. Missing keys and indexes
+// out of range simply match nothing.
+func (p path) eval(v interface{}) []interface{} {
+	values := []interface{}{v}
+	for _, segment := range p.segments {
+		next := []interface{}{}
+		for _, value := range values {
+			next = append(next, segment.eval(value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (s pathSegment) eval(v interface{}) []interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			values := make([]interface{}, 0, len(value))
+			for _, element := range value {
+				values = append(values, element)
+			}
+			return values
+		}
+		if element, ok := value[s.key]; ok && !s.isIndex {
+			return []interface{}{element}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return value
+		}
+		if s.isIndex && s.index >= 0 && s.index < len(value) {
+			return []interface{}{value[s.index]}
+		}
+	}
+	return nil
+}
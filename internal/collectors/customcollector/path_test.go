@@ -0,0 +1,83 @@
+package customcollector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		expected path
+		err      bool
+	}{
+		{expr: "", expected: path{}},
+		{expr: "$", expected: path{absolute: true}},
+		{expr: "Status.Health", expected: path{segments: []pathSegment{{key: "Status"}, {key: "Health"}}}},
+		{expr: "$.Id", expected: path{absolute: true, segments: []pathSegment{{key: "Id"}}}},
+		{expr: "PowerControl[*].MemberId", expected: path{segments: []pathSegment{{key: "PowerControl"}, {wildcard: true}, {key: "MemberId"}}}},
+		{expr: "Voltages[1]", expected: path{segments: []pathSegment{{key: "Voltages"}, {index: 1, isIndex: true}}}},
+		{expr: "Links['@odata.id']", expected: path{segments: []pathSegment{{key: "Links"}, {key: "@odata.id"}}}},
+		{expr: `Oem["Vendor.Name"]`, expected: path{segments: []pathSegment{{key: "Oem"}, {key: "Vendor.Name"}}}},
+		{expr: "Voltages[1", err: true},
+		{expr: "Voltages[first]", err: true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			p, err := parsePath(tc.expr)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, p)
+			}
+		})
+	}
+}
+
+func TestPathEval(t *testing.T) {
+	resource := map[string]interface{}{
+		"Id":     "1U",
+		"Status": map[string]interface{}{"Health": "OK"},
+		"Voltages": []interface{}{
+			map[string]interface{}{"MemberId": "0", "ReadingVolts": 12.0},
+			map[string]interface{}{"MemberId": "1", "ReadingVolts": 3.3},
+		},
+		"Oem": map[string]interface{}{
+			"Vendor": map[string]interface{}{"Fans": 4.0},
+		},
+		"@odata.id": "/redfish/v1/Chassis/1U",
+	}
+
+	for _, tc := range []struct {
+		expr     string
+		expected []interface{}
+	}{
+		{expr: "", expected: []interface{}{resource}},
+		{expr: "Status.Health", expected: []interface{}{"OK"}},
+		{expr: "Voltages[*].ReadingVolts", expected: []interface{}{12.0, 3.3}},
+		{expr: "Voltages[1].MemberId", expected: []interface{}{"1"}},
+		{expr: "Oem[*].Fans", expected: []interface{}{4.0}},
+		{expr: "['@odata.id']", expected: []interface{}{"/redfish/v1/Chassis/1U"}},
+		{expr: "Missing.Key", expected: []interface{}{}},
+		{expr: "Voltages[2]", expected: []interface{}{}},
+		{expr: "Voltages[-1]", expected: []interface{}{}},
+		{expr: "Id[0]", expected: []interface{}{}},
+		{expr: "Voltages.MemberId", expected: []interface{}{}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			p, err := parsePath(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.eval(resource); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package customcollector
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+)
+
+const wildcardSegment = "*"
+
+// resourceCache fetches raw Redfish resources and remembers them for the
+// duration of a single scrape, so metrics sharing a URI only fetch it once.
+type resourceCache struct {
+	client    *redfish.Client
+	mu        sync.Mutex
+	resources map[string]interface{}
+}
+
+func newResourceCache(client *redfish.Client) *resourceCache {
+	return &resourceCache{
+		client:    client,
+		resources: make(map[string]interface{}),
+	}
+}
+
+func (r *resourceCache) get(uri string) (interface{}, error) {
+	r.mu.Lock()
+	resource, ok := r.resources[uri]
+	r.mu.Unlock()
+	if ok {
+		return resource, nil
+	}
+
+	resp, err := r.client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&resource); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.resources[uri] = resource
+	r.mu.Unlock()
+	return resource, nil
+}
+
+// expand replaces every * segment of uri with the members of the collection
+// found at the preceding path, e.g. /redfish/v1/Chassis/*/Power becomes one
+// URI per chassis.
+func (r *resourceCache) expand(uri string) ([]string, error) {
+	segments := strings.Split(strings.TrimSuffix(uri, "/"), "/")
+	for i, segment := range segments {
+		if segment != wildcardSegment {
+			continue
+		}
+
+		collection, err := r.get(strings.Join(segments[:i], "/"))
+		if err != nil {
+			return nil, err
+		}
+		rest := strings.Join(segments[i+1:], "/")
+
+		uris := []string{}
+		for _, member := range memberLinks(collection) {
+			memberURI := strings.TrimSuffix(member, "/")
+			if rest != "" {
+				memberURI += "/" + rest
+			}
+			expanded, err := r.expand(memberURI)
+			if err != nil {
+				return nil, err
+			}
+			uris = append(uris, expanded...)
+		}
+		return uris, nil
+	}
+
+	return []string{uri}, nil
+}
+
+func memberLinks(collection interface{}) []string {
+	links := []string{}
+	object, ok := collection.(map[string]interface{})
+	if !ok {
+		return links
+	}
+	members, _ := object["Members"].([]interface{})
+	for _, member := range members {
+		link, ok := member.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := link["@odata.id"].(string); ok {
+			links = append(links, id)
+		}
+	}
+	return links
+}
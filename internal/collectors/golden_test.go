@@ -0,0 +1,165 @@
+package collectors_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/chassiscollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/customcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/oemcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/powerequipmentcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	redfishprometheus "github.com/FreekingDean/redfish_exporter/internal/prometheus"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/kylelemons/godebug/diff"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/pflag"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+const (
+	mockupsDir = "testdata/mockups"
+	goldenDir  = "testdata/golden"
+)
+
+// TestGolden runs all collectors against every mockup in testdata/mockups
+// and compares the output with testdata/golden/<case>.prom. The case
+// <mockup> uses the optional config <mockup>.yaml, further cases of the
+// same mockup are added with configs named <mockup>.<variant>.yaml.
+func TestGolden(t *testing.T) {
+	mockups, err := os.ReadDir(mockupsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mockup := range mockups {
+		if !mockup.IsDir() {
+			continue
+		}
+		variants, err := filepath.Glob(filepath.Join(goldenDir, mockup.Name()+".*.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases := []string{mockup.Name()}
+		for _, variant := range variants {
+			cases = append(cases, strings.TrimSuffix(filepath.Base(variant), ".yaml"))
+		}
+
+		for _, name := range cases {
+			t.Run(name, func(t *testing.T) {
+				got := scrapeMockup(t, filepath.Join(mockupsDir, mockup.Name()), filepath.Join(goldenDir, name+".yaml"))
+				golden := filepath.Join(goldenDir, name+".prom")
+				if *update {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v, run go test -update to create it", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from %s, run go test -update if this is expected:\n%s",
+						golden, diff.Diff(string(want), string(got)))
+				}
+			})
+		}
+	}
+}
+
+// scrapeMockup serves the mockup in dir and returns the exposition of all
+// collectors, as configured by configFile if it exists, like it is served
+// by the exporter.
+func scrapeMockup(t *testing.T, dir, configFile string) []byte {
+	t.Helper()
+
+	resources, err := redfishtest.LoadFixture(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := redfishtest.NewServer(resources)
+	t.Cleanup(server.Close)
+
+	flags := pflag.NewFlagSet("golden", pflag.ContinueOnError)
+	config.AddFlags(flags)
+	for key, value := range map[string]string{
+		"host.endpoint":  server.URL,
+		"host.username":  server.Username,
+		"host.password":  server.Password,
+		"host.basicAuth": "true",
+	} {
+		if err := flags.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := []config.Option{config.WithFlags(flags)}
+	if _, err := os.Stat(configFile); err == nil {
+		opts = append(opts, config.WithFilePath(configFile))
+	}
+
+	var (
+		cfg      config.Config
+		registry *collectors.Registry
+	)
+	app := fxtest.New(t,
+		fx.NopLogger,
+		fx.Supply(opts),
+		fx.Provide(
+			func() *log.Logger { return &log.Logger{Logger: zap.NewNop()} },
+			config.New,
+			tracing.New,
+			redfish.NewRequestMetrics,
+			redfish.NewClientConfig,
+			redfish.NewClient,
+			collectors.NewRegistry,
+			collectors.NewScrapeStatus,
+			chassiscollector.New,
+			powerequipmentcollector.New,
+			oemcollector.New,
+			customcollector.New,
+		),
+		fx.Invoke(
+			collectors.RegisterScrapeStatus,
+			chassiscollector.Register,
+			powerequipmentcollector.Register,
+			oemcollector.Register,
+			customcollector.Register,
+		),
+		fx.Populate(&cfg, &registry),
+	)
+	app.RequireStart()
+	t.Cleanup(app.RequireStop)
+
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return registry.GatherContext(context.Background())
+	})
+	families, err := redfishprometheus.EncodingGatherer(gatherer, cfg.Metrics).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
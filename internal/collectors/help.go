@@ -1,17 +1,37 @@
 package collectors
 
-import "fmt"
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 const (
-	CommonHealthHelp   = "1(OK),2(Warning),3(Critical)"
-	CommonSeverityHelp = CommonHealthHelp
-	CommonStateHelp    = "1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)"
+	CommonHealthHelp       = "1(OK),2(Warning),3(Critical)"
+	CommonSeverityHelp     = CommonHealthHelp
+	CommonStateHelp        = "1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)"
+	CommonPowerStateHelp   = "1(On),2(Off),3(PoweringOn),4(PoweringOff),5(Paused)"
+	CommonBreakerStateHelp = "1(Normal),2(Tripped),3(Off)"
 )
 
-func HealthHelp(component string) string {
-	return fmt.Sprintf("health of %s,%s", component, CommonHealthHelp)
+// NewHealthDesc returns the descriptor of the health metric of component,
+// valued by HealthToFloat.
+func NewHealthDesc(fqName, component string, variableLabels []string) *prometheus.Desc {
+	return newEnumDesc(fqName, "health of "+component, CommonHealthHelp, healthStates, variableLabels)
+}
+
+// NewStateDesc returns the descriptor of the state metric of component,
+// valued by StateToFloat.
+func NewStateDesc(fqName, component string, variableLabels []string) *prometheus.Desc {
+	return newEnumDesc(fqName, "state of "+component, CommonStateHelp, commonStates, variableLabels)
+}
+
+// NewPowerStateDesc returns the descriptor of the power state metric of
+// component, valued by PowerStateToFloat.
+func NewPowerStateDesc(fqName, component string, variableLabels []string) *prometheus.Desc {
+	return newEnumDesc(fqName, "power state of "+component, CommonPowerStateHelp, powerStates, variableLabels)
 }
 
-func StateHelp(component string) string {
-	return fmt.Sprintf("state of %s,%s", component, CommonStateHelp)
+// NewBreakerStateDesc returns the descriptor of the breaker state metric of
+// component, valued by BreakerStateToFloat.
+func NewBreakerStateDesc(fqName, component string, variableLabels []string) *prometheus.Desc {
+	return newEnumDesc(fqName, "breaker state of "+component, CommonBreakerStateHelp, breakerStates, variableLabels)
 }
@@ -1,5 +1,10 @@
 package collectors
 
+import "github.com/FreekingDean/redfish_exporter/internal/redfish"
+
 const (
-	Namespace = "redfish"
+	Namespace = redfish.Namespace
+	// ExporterNamespace is the namespace of the metrics about the exporter
+	// itself rather than the BMC.
+	ExporterNamespace = redfish.ExporterNamespace
 )
@@ -0,0 +1,174 @@
+package collectors
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	NamingLegacy = "legacy"
+	NamingV2     = "v2"
+	NamingBoth   = "both"
+
+	resourceLabel = "resource"
+)
+
+// v2Prefixes are the families of the built-in collectors. Their resource
+// label only repeats the kind of component already in the metric name and is
+// dropped in the v2 naming scheme. Custom metrics are left alone.
+var v2Prefixes = []string{
+	Namespace + "_chassis_",
+	Namespace + "_pdu_",
+	Namespace + "_oem_",
+}
+
+type v2Family struct {
+	name string
+	help string
+	// scale converts the legacy value to the base unit.
+	scale float64
+	// labels renames labels, taking precedence over dropping resource.
+	labels map[string]string
+}
+
+// v2Families lists the families whose name, unit or labels change in the v2
+// naming scheme, keyed by their legacy name.
+var v2Families = map[string]v2Family{
+	chassisName("health"):     {labels: chassisV2Labels},
+	chassisName("state"):      {labels: chassisV2Labels},
+	chassisName("model_info"): {labels: chassisV2Labels},
+
+	chassisName("fan_rpm_percentage"): {
+		name:  chassisName("fan_speed_ratio"),
+		help:  "fan speed as a ratio of its maximum speed",
+		scale: 0.01,
+	},
+	chassisName("power_power_supply_efficiency_percentage"): {
+		name:  chassisName("power_supply_efficiency_ratio"),
+		help:  "power supply efficiency as a ratio",
+		scale: 0.01,
+	},
+	chassisName("power_power_supply_health"):                  {name: chassisName("power_supply_health")},
+	chassisName("power_power_supply_state"):                   {name: chassisName("power_supply_state")},
+	chassisName("power_power_supply_input_watts"):             {name: chassisName("power_supply_input_watts")},
+	chassisName("power_power_supply_output_watts"):            {name: chassisName("power_supply_output_watts")},
+	chassisName("power_power_supply_power_capacity_watts"):    {name: chassisName("power_supply_capacity_watts")},
+	chassisName("power_power_supply_last_power_output_watts"): {name: chassisName("power_supply_last_output_watts")},
+
+	chassisName("network_adapter_tx_bytes"): {name: chassisName("network_adapter_transmit_bytes_total")},
+	chassisName("network_adapter_rx_bytes"): {name: chassisName("network_adapter_receive_bytes_total")},
+
+	pduName("outlet_energy_kwh"): {
+		name:  pduName("outlet_energy_joules_total"),
+		help:  "energy consumed by the outlet in joules",
+		scale: 3.6e6,
+	},
+	pduName("circuit_energy_kwh"): {
+		name:  pduName("circuit_energy_joules_total"),
+		help:  "energy consumed by the circuit in joules",
+		scale: 3.6e6,
+	},
+}
+
+// chassisName and pduName return the names of the families of the chassis
+// and power equipment collectors.
+func chassisName(name string) string {
+	return prometheus.BuildFQName(Namespace, "chassis", name)
+}
+
+func pduName(name string) string {
+	return prometheus.BuildFQName(Namespace, "pdu", name)
+}
+
+// The legacy chassis metrics carry the chassis ID in resource and its name in
+// chassis_id, unlike every other chassis metric.
+var chassisV2Labels = map[string]string{
+	resourceLabel: "chassis_id",
+	"chassis_id":  "chassis",
+}
+
+// NamingGatherer renames the families gathered by g to the given naming
+// scheme. With NamingBoth the families that are renamed are exposed under
+// both names, the others keep their legacy labels.
+func NamingGatherer(g prometheus.Gatherer, naming string) prometheus.Gatherer {
+	if naming != NamingV2 && naming != NamingBoth {
+		return g
+	}
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		renamed := make([]*dto.MetricFamily, 0, len(families))
+		for _, family := range families {
+			v2 := toV2(family)
+			if naming == NamingBoth {
+				renamed = append(renamed, family)
+				if v2.GetName() == family.GetName() {
+					continue
+				}
+			}
+			renamed = append(renamed, v2)
+		}
+		return renamed, err
+	})
+}
+
+func toV2(family *dto.MetricFamily) *dto.MetricFamily {
+	spec, ok := v2Families[family.GetName()]
+	if !ok && !hasV2Prefix(family.GetName()) {
+		return family
+	}
+
+	v2 := &dto.MetricFamily{
+		Name: family.Name,
+		Help: family.Help,
+		Type: family.Type,
+	}
+	if spec.name != "" {
+		v2.Name = proto.String(spec.name)
+	}
+	if spec.help != "" {
+		v2.Help = proto.String(spec.help)
+	}
+
+	for _, metric := range family.Metric {
+		m := &dto.Metric{TimestampMs: metric.TimestampMs}
+		for _, label := range metric.Label {
+			name, renamed := spec.labels[label.GetName()]
+			switch {
+			case renamed:
+				m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(name), Value: label.Value})
+			case label.GetName() != resourceLabel:
+				m.Label = append(m.Label, label)
+			}
+		}
+
+		scale := spec.scale
+		if scale == 0 {
+			scale = 1
+		}
+		switch {
+		case metric.Gauge != nil:
+			m.Gauge = &dto.Gauge{Value: proto.Float64(metric.Gauge.GetValue() * scale)}
+		case metric.Counter != nil:
+			m.Counter = &dto.Counter{Value: proto.Float64(metric.Counter.GetValue() * scale)}
+		default:
+			m.Untyped = metric.Untyped
+			m.Summary = metric.Summary
+			m.Histogram = metric.Histogram
+		}
+		v2.Metric = append(v2.Metric, m)
+	}
+	return v2
+}
+
+func hasV2Prefix(name string) bool {
+	for _, prefix := range v2Prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
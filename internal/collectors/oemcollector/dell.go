@@ -0,0 +1,68 @@
+package oemcollector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	dellSystemRollupHealthMetric = "dell_system_rollup_health"
+
+	dellRollupSuffix = "RollupStatus"
+)
+
+var (
+	dellRollupLabels = []string{"rollup"}
+)
+
+func dellMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		dellSystemRollupHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, dellSystemRollupHealthMetric),
+			"the DellSystem rollup status",
+			append(labels, dellRollupLabels...),
+		),
+	}
+}
+
+// collectDellSystemMetrics exports the *RollupStatus properties of
+// Oem.Dell.DellSystem, which iDRAC computes over all components of a type.
+func (c *Collector) collectDellSystemMetrics(ch chan<- prometheus.Metric, system *redfish.ComputerSystem) error {
+	c.logger.Debug("Collecting dell system metrics")
+	section, ok := vendorOem(system.OEM, "Dell")
+	if !ok {
+		c.logger.Debug(fmt.Sprintf("No dell oem information for system %s", system.ID))
+		return nil
+	}
+
+	var oem struct {
+		DellSystem map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(section, &oem); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to parse dell oem information for system %s", system.ID), zap.Error(err))
+		return err
+	}
+
+	for key, raw := range oem.DellSystem {
+		if !strings.HasSuffix(key, dellRollupSuffix) {
+			continue
+		}
+		var status redfish.Health
+		if err := json.Unmarshal(raw, &status); err != nil {
+			continue
+		}
+
+		labelValues := []string{"system", system.ID, strings.ToLower(strings.TrimSuffix(key, dellRollupSuffix))}
+		if health, ok := collectors.HealthToFloat(status); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[dellSystemRollupHealthMetric], prometheus.GaugeValue, health, labelValues...)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package oemcollector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	hpeAggregateHealthMetric          = "hpe_aggregate_health"
+	hpeSmartStorageHealthMetric       = "hpe_smart_storage_health"
+	hpeArrayControllerHealthMetric    = "hpe_array_controller_health"
+	hpeArrayControllerStateMetric     = "hpe_array_controller_state"
+	hpeArrayControllerModelInfoMetric = "hpe_array_controller_model_info"
+)
+
+var (
+	hpeAggregateLabels       = []string{"component"}
+	hpeArrayControllerLabels = []string{"array_controller", "array_controller_id"}
+)
+
+type hpeStatus struct {
+	Status struct {
+		Health redfish.Health
+		State  redfish.State
+	}
+}
+
+func hpeMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		hpeAggregateHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, hpeAggregateHealthMetric),
+			"the iLO AggregateHealthStatus component",
+			append(labels, hpeAggregateLabels...),
+		),
+		hpeSmartStorageHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, hpeSmartStorageHealthMetric),
+			"the SmartStorage subsystem",
+			labels,
+		),
+		hpeArrayControllerHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, hpeArrayControllerHealthMetric),
+			"the SmartStorage array controller",
+			append(labels, hpeArrayControllerLabels...),
+		),
+		hpeArrayControllerStateMetric: collectors.NewStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, hpeArrayControllerStateMetric),
+			"the SmartStorage array controller",
+			append(labels, hpeArrayControllerLabels...),
+		),
+		hpeArrayControllerModelInfoMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, hpeArrayControllerModelInfoMetric),
+			"model and firmware version of the SmartStorage array controller",
+			append(labels, append(hpeArrayControllerLabels, "model", "firmware_version")...),
+			nil,
+		),
+	}
+}
+
+// hpeSystemOem returns the Hpe section of the system's Oem property. iLO 4
+// uses "Hp" as the key while iLO 5 and later use "Hpe".
+func hpeSystemOem(system *redfish.ComputerSystem) (json.RawMessage, bool) {
+	return vendorOem(system.OEM, "Hpe", "Hp")
+}
+
+func (c *Collector) collectHPEAggregateHealthMetrics(ch chan<- prometheus.Metric, system *redfish.ComputerSystem) error {
+	c.logger.Debug("Collecting hpe aggregate health metrics")
+	section, ok := hpeSystemOem(system)
+	if !ok {
+		c.logger.Debug(fmt.Sprintf("No hpe oem information for system %s", system.ID))
+		return nil
+	}
+
+	var oem struct {
+		AggregateHealthStatus map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(section, &oem); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to parse hpe oem information for system %s", system.ID), zap.Error(err))
+		return err
+	}
+
+	for component, raw := range oem.AggregateHealthStatus {
+		// Not every entry is a status object, e.g. FanRedundancy is a plain
+		// string. Those are skipped.
+		status := hpeStatus{}
+		if err := json.Unmarshal(raw, &status); err != nil {
+			continue
+		}
+
+		labelValues := []string{"system", system.ID, strings.ToLower(component)}
+		if health, ok := collectors.HealthToFloat(status.Status.Health); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[hpeAggregateHealthMetric], prometheus.GaugeValue, health, labelValues...)
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) collectHPESmartStorageMetrics(ch chan<- prometheus.Metric, system *redfish.ComputerSystem) error {
+	c.logger.Debug("Collecting hpe smart storage metrics")
+	section, ok := hpeSystemOem(system)
+	if !ok {
+		return nil
+	}
+
+	var oem struct {
+		Links struct {
+			SmartStorage redfish.Link
+		}
+	}
+	if err := json.Unmarshal(section, &oem); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to parse hpe oem information for system %s", system.ID), zap.Error(err))
+		return err
+	} else if oem.Links.SmartStorage == "" {
+		c.logger.Debug(fmt.Sprintf("No hpe smart storage information for system %s", system.ID))
+		return nil
+	}
+
+	var smartStorage struct {
+		hpeStatus
+		Links struct {
+			ArrayControllers redfish.Link
+		}
+	}
+	if err := c.getJSON(system, string(oem.Links.SmartStorage), &smartStorage); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to get hpe smart storage information for system %s", system.ID), zap.Error(err))
+		return err
+	}
+
+	labelValues := []string{"smart_storage", system.ID}
+	if health, ok := collectors.HealthToFloat(smartStorage.Status.Health); ok {
+		ch <- prometheus.MustNewConstMetric(c.metrics[hpeSmartStorageHealthMetric], prometheus.GaugeValue, health, labelValues...)
+	}
+
+	if smartStorage.Links.ArrayControllers == "" {
+		return nil
+	}
+	var controllers struct {
+		Members []redfish.Link
+	}
+	if err := c.getJSON(system, string(smartStorage.Links.ArrayControllers), &controllers); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to get hpe array controllers for system %s", system.ID), zap.Error(err))
+		return err
+	}
+
+	var errs []error
+	for _, member := range controllers.Members {
+		var controller struct {
+			hpeStatus
+			ID              string `json:"Id"`
+			Name            string
+			Model           string
+			FirmwareVersion struct {
+				Current struct {
+					VersionString string
+				}
+			}
+		}
+		if err := c.getJSON(system, string(member), &controller); err != nil {
+			c.logger.Error(fmt.Sprintf("Failed to get hpe array controller %s", member), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+
+		controllerLabels := []string{"array_controller", system.ID, controller.Name, controller.ID}
+		if health, ok := collectors.HealthToFloat(controller.Status.Health); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[hpeArrayControllerHealthMetric], prometheus.GaugeValue, health, controllerLabels...)
+		}
+		if state, ok := collectors.StateToFloat(controller.Status.State); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[hpeArrayControllerStateMetric], prometheus.GaugeValue, state, controllerLabels...)
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics[hpeArrayControllerModelInfoMetric], prometheus.GaugeValue, 1,
+			append(controllerLabels, controller.Model, controller.FirmwareVersion.Current.VersionString)...)
+	}
+
+	return errors.Join(errs...)
+}
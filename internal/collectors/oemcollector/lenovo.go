@@ -0,0 +1,69 @@
+package oemcollector
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	lenovoFrontPanelUSBInfoMetric              = "lenovo_front_panel_usb_info"
+	lenovoFrontPanelUSBInactivityTimeoutMetric = "lenovo_front_panel_usb_inactivity_timeout_seconds"
+)
+
+var (
+	lenovoFrontPanelUSBLabels = []string{"mode", "port_switching_to"}
+)
+
+func lenovoMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		lenovoFrontPanelUSBInfoMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, lenovoFrontPanelUSBInfoMetric),
+			"front panel USB port mode and which side (BMC or server) the port is currently switched to",
+			append(labels, lenovoFrontPanelUSBLabels...),
+			nil,
+		),
+		lenovoFrontPanelUSBInactivityTimeoutMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, lenovoFrontPanelUSBInactivityTimeoutMetric),
+			"inactivity timeout after which a shared front panel USB port switches back to the server",
+			labels,
+			nil,
+		),
+	}
+}
+
+func (c *Collector) collectLenovoFrontPanelUSBMetrics(ch chan<- prometheus.Metric, system *redfish.ComputerSystem) error {
+	c.logger.Debug("Collecting lenovo front panel usb metrics")
+	section, ok := vendorOem(system.OEM, "Lenovo")
+	if !ok {
+		c.logger.Debug(fmt.Sprintf("No lenovo oem information for system %s", system.ID))
+		return nil
+	}
+
+	var oem struct {
+		FrontPanelUSB *struct {
+			FPMode                string
+			PortSwitchingTo       string
+			InactivityTimeoutMins *float64
+		}
+	}
+	if err := json.Unmarshal(section, &oem); err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to parse lenovo oem information for system %s", system.ID), zap.Error(err))
+		return err
+	} else if oem.FrontPanelUSB == nil {
+		c.logger.Debug(fmt.Sprintf("No lenovo front panel usb information for system %s", system.ID))
+		return nil
+	}
+
+	labelValues := []string{"front_panel_usb", system.ID}
+	usb := oem.FrontPanelUSB
+	ch <- prometheus.MustNewConstMetric(c.metrics[lenovoFrontPanelUSBInfoMetric], prometheus.GaugeValue, 1, append(labelValues, usb.FPMode, usb.PortSwitchingTo)...)
+	if usb.InactivityTimeoutMins != nil {
+		ch <- prometheus.MustNewConstMetric(c.metrics[lenovoFrontPanelUSBInactivityTimeoutMetric], prometheus.GaugeValue, *usb.InactivityTimeoutMins*60, labelValues...)
+	}
+	return nil
+}
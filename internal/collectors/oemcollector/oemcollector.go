@@ -0,0 +1,172 @@
+package oemcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const (
+	subsystem = "oem"
+)
+
+var (
+	labels           = []string{"resource", "system_id"}
+	vendorInfoMetric = "vendor_info"
+)
+
+// collectorFunc exports the metrics of a system's Oem section. It returns an
+// error if the section or a resource it links to could not be read, a
+// missing section is not an error.
+type collectorFunc func(chan<- prometheus.Metric, *redfish.ComputerSystem) error
+
+// Collector exports data that vendors only expose in the Oem sections of
+// their resources. The vendor is detected from the service root and only
+// the collectorFuncs registered for that vendor are run.
+type Collector struct {
+	logger         *log.Logger
+	redfish        *redfish.Client
+	metrics        map[string]*prometheus.Desc
+	scrapeStatus   *collectors.ScrapeStatus
+	tracer         trace.Tracer
+	collectorFuncs map[vendor][]collectorFunc
+}
+
+func New(logger *log.Logger, client *redfish.Client, scrapeStatus *collectors.ScrapeStatus, tracer trace.Tracer) *Collector {
+	return &Collector{
+		logger:         logger,
+		redfish:        client,
+		metrics:        make(map[string]*prometheus.Desc),
+		scrapeStatus:   scrapeStatus,
+		tracer:         tracer,
+		collectorFuncs: make(map[vendor][]collectorFunc),
+	}
+}
+
+func Register(collector *Collector, registry *collectors.Registry, cfg config.Config, lc fx.Lifecycle) {
+	if !cfg.Collectors.OEM {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			metricGroups := []map[string]*prometheus.Desc{
+				vendorMetrics(),
+				dellMetrics(),
+				hpeMetrics(),
+				lenovoMetrics(),
+				supermicroMetrics(),
+			}
+			for _, metrics := range metricGroups {
+				for metricName, metric := range metrics {
+					collector.metrics[metricName] = metric
+				}
+			}
+			collector.collectorFuncs = map[vendor][]collectorFunc{
+				vendorDell: {
+					collector.collectDellSystemMetrics,
+				},
+				vendorHPE: {
+					collector.collectHPEAggregateHealthMetrics,
+					collector.collectHPESmartStorageMetrics,
+				},
+				vendorLenovo: {
+					collector.collectLenovoFrontPanelUSBMetrics,
+				},
+				vendorSupermicro: {
+					collector.collectSupermicroNodeManagerMetrics,
+				},
+			}
+
+			return registry.Register(collector)
+		},
+	})
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *Collector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Debug("Collecting oem metrics")
+	ctx, span := c.tracer.Start(ctx, "oem")
+	defer span.End()
+
+	client, err := c.redfish.WithContext(ctx)
+	if err != nil {
+		c.logger.Error("Failed to connect to redfish service", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("oem").Set(float64(0))
+		return
+	}
+
+	service := client.GetService()
+	vendor := detectVendor(service)
+	ch <- prometheus.MustNewConstMetric(c.metrics[vendorInfoMetric], prometheus.GaugeValue, 1, string(vendor))
+
+	collectorFuncs := c.collectorFuncs[vendor]
+	if len(collectorFuncs) == 0 {
+		c.logger.Debug(fmt.Sprintf("No oem collectors for vendor %s", vendor))
+		c.scrapeStatus.WithLabelValues("oem").Set(float64(1))
+		return
+	}
+
+	systems, err := service.Systems()
+	if err != nil {
+		c.logger.Error("Failed to get systems", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("oem").Set(float64(0))
+		return
+	}
+
+	var failed atomic.Bool
+	wg := sync.WaitGroup{}
+	for _, system := range systems {
+		for _, collectorFunc := range collectorFuncs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := collectorFunc(ch, system); err != nil {
+					tracing.Fail(span, err)
+					failed.Store(true)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	c.logger.Debug("Finished collecting oem metrics")
+	if failed.Load() {
+		c.scrapeStatus.WithLabelValues("oem").Set(float64(0))
+		return
+	}
+	c.scrapeStatus.WithLabelValues("oem").Set(float64(1))
+}
+
+// getJSON fetches a resource that gofish has no type for and decodes it into v.
+func (c *Collector) getJSON(system *redfish.ComputerSystem, uri string, v interface{}) error {
+	resp, err := system.GetClient().Get(uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
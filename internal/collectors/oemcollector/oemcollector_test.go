@@ -0,0 +1,392 @@
+package oemcollector_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/oemcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const (
+	systemsURI = "/redfish/v1/Systems"
+	systemURI  = systemsURI + "/1"
+)
+
+// The Oem sections below are trimmed captures from the respective BMCs.
+const (
+	dellOem = `{
+  "Dell": {
+    "DellSystem": {
+      "@odata.id": "/redfish/v1/Systems/System.Embedded.1/Oem/Dell/DellSystems/System.Embedded.1",
+      "BIOSReleaseDate": "09/14/2023",
+      "CPURollupStatus": "OK",
+      "CoolingRollupStatus": "OK",
+      "FanRollupStatus": "Warning",
+      "PSRollupStatus": "Critical",
+      "StorageRollupStatus": "OK",
+      "TempRollupStatus": "OK",
+      "LastSystemInventoryTime": "2024-01-10T09:37:59+00:00"
+    }
+  }
+}`
+
+	hpeOem = `{
+  "Hpe": {
+    "@odata.type": "#HpeComputerSystemExt.v2_11_0.HpeComputerSystemExt",
+    "AggregateHealthStatus": {
+      "AgentlessManagementService": "Ready",
+      "BiosOrHardwareHealth": {"Status": {"Health": "OK"}},
+      "FanRedundancy": "Redundant",
+      "Fans": {"Status": {"Health": "OK"}},
+      "Memory": {"Status": {"Health": "Warning"}},
+      "PowerSupplyRedundancy": "Redundant",
+      "Storage": {"Status": {"Health": "OK"}}
+    },
+    "Links": {
+      "SmartStorage": {"@odata.id": "/redfish/v1/Systems/1/SmartStorage"}
+    }
+  }
+}`
+
+	// iLO 4 uses Hp instead of Hpe.
+	hpOem = `{
+  "Hp": {
+    "AggregateHealthStatus": {
+      "Processors": {"Status": {"Health": "OK"}}
+    }
+  }
+}`
+
+	lenovoOem = `{
+  "Lenovo": {
+    "@odata.type": "#LenovoComputerSystem.v1_0_0.LenovoComputerSystem",
+    "FrontPanelUSB": {
+      "FPMode": "Shared",
+      "IDButton": "On",
+      "InactivityTimeoutMins": 5,
+      "PortSwitchingTo": "Server"
+    },
+    "TotalPowerOnHours": 8745
+  }
+}`
+
+	supermicroOem = `{
+  "Supermicro": {
+    "@odata.type": "#SmcSystemExtensions.v1_0_0.System",
+    "NodeManager": {"@odata.id": "/redfish/v1/Systems/1/Oem/Supermicro/NodeManager"}
+  }
+}`
+)
+
+// newGatherer serves a service root of vendor with a system whose Oem
+// section is oem, plus resources.
+func newGatherer(t *testing.T, vendor, oem string, resources map[string]redfishtest.Resource) prometheus.Gatherer {
+	t.Helper()
+
+	tree := redfishtest.DefaultTree()
+	tree[redfishtest.ServiceRoot]["Vendor"] = vendor
+	tree[systemsURI] = redfishtest.Collection(systemsURI, systemURI)
+	tree[systemURI] = redfishtest.Resource{
+		"@odata.id": systemURI,
+		"Id":        "1",
+		"Name":      "System",
+		"Oem":       json.RawMessage(oem),
+	}
+	for uri, resource := range resources {
+		tree[uri] = resource
+	}
+	server := redfishtest.NewServer(tree)
+	t.Cleanup(server.Close)
+
+	cfg := config.Config{
+		Host: config.Host{
+			Endpoint: server.URL,
+			Username: server.Username,
+			Password: server.Password,
+		},
+		Collectors: config.Collectors{OEM: true},
+	}
+	tracer := noop.NewTracerProvider().Tracer("")
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := collectors.NewRegistry()
+	scrapeStatus := collectors.NewScrapeStatus()
+	lc := fxtest.NewLifecycle(t)
+	collectors.RegisterScrapeStatus(scrapeStatus, registry, lc)
+	oemcollector.Register(oemcollector.New(logger, client, scrapeStatus, tracer), registry, cfg, lc)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return registry.GatherContext(context.Background())
+	})
+}
+
+func TestCollector(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		vendor    string
+		oem       string
+		resources map[string]redfishtest.Resource
+		names     []string
+		absent    []string
+		expected  string
+	}{
+		{
+			name:   "dell",
+			vendor: "Dell",
+			oem:    dellOem,
+			names:  []string{"redfish_oem_vendor_info", "redfish_oem_dell_system_rollup_health"},
+			expected: `
+# HELP redfish_oem_dell_system_rollup_health health of the DellSystem rollup status,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_dell_system_rollup_health gauge
+redfish_oem_dell_system_rollup_health{resource="system",rollup="cooling",system_id="1"} 1
+redfish_oem_dell_system_rollup_health{resource="system",rollup="cpu",system_id="1"} 1
+redfish_oem_dell_system_rollup_health{resource="system",rollup="fan",system_id="1"} 2
+redfish_oem_dell_system_rollup_health{resource="system",rollup="ps",system_id="1"} 3
+redfish_oem_dell_system_rollup_health{resource="system",rollup="storage",system_id="1"} 1
+redfish_oem_dell_system_rollup_health{resource="system",rollup="temp",system_id="1"} 1
+# HELP redfish_oem_vendor_info vendor of the redfish service as detected from the service root
+# TYPE redfish_oem_vendor_info gauge
+redfish_oem_vendor_info{vendor="dell"} 1
+`,
+		},
+		{
+			name:   "hpe",
+			vendor: "HPE",
+			oem:    hpeOem,
+			resources: map[string]redfishtest.Resource{
+				"/redfish/v1/Systems/1/SmartStorage": {
+					"@odata.id": "/redfish/v1/Systems/1/SmartStorage",
+					"Status":    redfishtest.Resource{"Health": "OK"},
+					"Links": redfishtest.Resource{
+						"ArrayControllers": redfishtest.Link("/redfish/v1/Systems/1/SmartStorage/ArrayControllers"),
+					},
+				},
+				"/redfish/v1/Systems/1/SmartStorage/ArrayControllers": redfishtest.Collection(
+					"/redfish/v1/Systems/1/SmartStorage/ArrayControllers",
+					"/redfish/v1/Systems/1/SmartStorage/ArrayControllers/0",
+				),
+				"/redfish/v1/Systems/1/SmartStorage/ArrayControllers/0": {
+					"@odata.id":       "/redfish/v1/Systems/1/SmartStorage/ArrayControllers/0",
+					"Id":              "0",
+					"Name":            "HpeSmartStorageArrayController",
+					"Model":           "HPE Smart Array P408i-a SR Gen10",
+					"FirmwareVersion": redfishtest.Resource{"Current": redfishtest.Resource{"VersionString": "3.53"}},
+					"Status":          redfishtest.Resource{"Health": "Warning", "State": "Enabled"},
+				},
+			},
+			names: []string{
+				"redfish_oem_hpe_aggregate_health",
+				"redfish_oem_hpe_smart_storage_health",
+				"redfish_oem_hpe_array_controller_health",
+				"redfish_oem_hpe_array_controller_state",
+				"redfish_oem_hpe_array_controller_model_info",
+			},
+			expected: `
+# HELP redfish_oem_hpe_aggregate_health health of the iLO AggregateHealthStatus component,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_hpe_aggregate_health gauge
+redfish_oem_hpe_aggregate_health{component="biosorhardwarehealth",resource="system",system_id="1"} 1
+redfish_oem_hpe_aggregate_health{component="fans",resource="system",system_id="1"} 1
+redfish_oem_hpe_aggregate_health{component="memory",resource="system",system_id="1"} 2
+redfish_oem_hpe_aggregate_health{component="storage",resource="system",system_id="1"} 1
+# HELP redfish_oem_hpe_array_controller_health health of the SmartStorage array controller,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_hpe_array_controller_health gauge
+redfish_oem_hpe_array_controller_health{array_controller="HpeSmartStorageArrayController",array_controller_id="0",resource="array_controller",system_id="1"} 2
+# HELP redfish_oem_hpe_array_controller_model_info model and firmware version of the SmartStorage array controller
+# TYPE redfish_oem_hpe_array_controller_model_info gauge
+redfish_oem_hpe_array_controller_model_info{array_controller="HpeSmartStorageArrayController",array_controller_id="0",firmware_version="3.53",model="HPE Smart Array P408i-a SR Gen10",resource="array_controller",system_id="1"} 1
+# HELP redfish_oem_hpe_array_controller_state state of the SmartStorage array controller,1(Enabled),2(Disabled),3(StandbyOffinline),4(StandbySpare),5(InTest),6(Starting),7(Absent),8(UnavailableOffline),9(Deferring),10(Quiesced),11(Updating)
+# TYPE redfish_oem_hpe_array_controller_state gauge
+redfish_oem_hpe_array_controller_state{array_controller="HpeSmartStorageArrayController",array_controller_id="0",resource="array_controller",system_id="1"} 1
+# HELP redfish_oem_hpe_smart_storage_health health of the SmartStorage subsystem,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_hpe_smart_storage_health gauge
+redfish_oem_hpe_smart_storage_health{resource="smart_storage",system_id="1"} 1
+`,
+		},
+		{
+			name:   "hp",
+			vendor: "HP",
+			oem:    hpOem,
+			names:  []string{"redfish_oem_vendor_info", "redfish_oem_hpe_aggregate_health"},
+			expected: `
+# HELP redfish_oem_hpe_aggregate_health health of the iLO AggregateHealthStatus component,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_hpe_aggregate_health gauge
+redfish_oem_hpe_aggregate_health{component="processors",resource="system",system_id="1"} 1
+# HELP redfish_oem_vendor_info vendor of the redfish service as detected from the service root
+# TYPE redfish_oem_vendor_info gauge
+redfish_oem_vendor_info{vendor="hpe"} 1
+`,
+		},
+		{
+			name:   "lenovo",
+			vendor: "Lenovo",
+			oem:    lenovoOem,
+			names: []string{
+				"redfish_oem_lenovo_front_panel_usb_info",
+				"redfish_oem_lenovo_front_panel_usb_inactivity_timeout_seconds",
+			},
+			expected: `
+# HELP redfish_oem_lenovo_front_panel_usb_inactivity_timeout_seconds inactivity timeout after which a shared front panel USB port switches back to the server
+# TYPE redfish_oem_lenovo_front_panel_usb_inactivity_timeout_seconds gauge
+redfish_oem_lenovo_front_panel_usb_inactivity_timeout_seconds{resource="front_panel_usb",system_id="1"} 300
+# HELP redfish_oem_lenovo_front_panel_usb_info front panel USB port mode and which side (BMC or server) the port is currently switched to
+# TYPE redfish_oem_lenovo_front_panel_usb_info gauge
+redfish_oem_lenovo_front_panel_usb_info{mode="Shared",port_switching_to="Server",resource="front_panel_usb",system_id="1"} 1
+`,
+		},
+		{
+			name:   "lenovo missing inactivity timeout",
+			vendor: "Lenovo",
+			oem:    `{"Lenovo": {"FrontPanelUSB": {"FPMode": "Server", "PortSwitchingTo": "Server"}}}`,
+			names:  []string{"redfish_oem_lenovo_front_panel_usb_info"},
+			absent: []string{"redfish_oem_lenovo_front_panel_usb_inactivity_timeout_seconds"},
+			expected: `
+# HELP redfish_oem_lenovo_front_panel_usb_info front panel USB port mode and which side (BMC or server) the port is currently switched to
+# TYPE redfish_oem_lenovo_front_panel_usb_info gauge
+redfish_oem_lenovo_front_panel_usb_info{mode="Server",port_switching_to="Server",resource="front_panel_usb",system_id="1"} 1
+`,
+		},
+		{
+			name:   "supermicro",
+			vendor: "Supermicro",
+			oem:    supermicroOem,
+			resources: map[string]redfishtest.Resource{
+				"/redfish/v1/Systems/1/Oem/Supermicro/NodeManager": {
+					"@odata.id": "/redfish/v1/Systems/1/Oem/Supermicro/NodeManager",
+					"Id":        "NodeManager",
+					"Statistics": []interface{}{
+						redfishtest.Resource{
+							"Mode":            "Global power statistics",
+							"DomainID":        "Entire platform",
+							"Timestamp":       "2024-10-14T16:58:30:+00:00",
+							"CurrentValue":    312,
+							"MaximumValue":    587,
+							"MinimumValue":    88,
+							"AverageValue":    296,
+							"ReportingPeriod": 94032,
+						},
+					},
+				},
+			},
+			names: []string{"redfish_oem_supermicro_node_manager_current", "redfish_oem_supermicro_node_manager_average"},
+			expected: `
+# HELP redfish_oem_supermicro_node_manager_average average value reported by the Intel node manager statistic over its reporting period
+# TYPE redfish_oem_supermicro_node_manager_average gauge
+redfish_oem_supermicro_node_manager_average{domain_id="Entire platform",mode="Global power statistics",resource="node_manager",system_id="1"} 296
+# HELP redfish_oem_supermicro_node_manager_current current value reported by the Intel node manager statistic, watts for power statistics
+# TYPE redfish_oem_supermicro_node_manager_current gauge
+redfish_oem_supermicro_node_manager_current{domain_id="Entire platform",mode="Global power statistics",resource="node_manager",system_id="1"} 312
+`,
+		},
+		{
+			name:   "unknown vendor",
+			vendor: "Contoso",
+			oem:    `{}`,
+			names:  []string{"redfish_oem_vendor_info"},
+			expected: `
+# HELP redfish_oem_vendor_info vendor of the redfish service as detected from the service root
+# TYPE redfish_oem_vendor_info gauge
+redfish_oem_vendor_info{vendor="unknown"} 1
+`,
+		},
+		{
+			name:   "missing oem section",
+			vendor: "Dell",
+			oem:    `{}`,
+			names:  []string{"redfish_oem_vendor_info", "redfish_collector_scrape_status"},
+			expected: `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="oem"} 1
+# HELP redfish_oem_vendor_info vendor of the redfish service as detected from the service root
+# TYPE redfish_oem_vendor_info gauge
+redfish_oem_vendor_info{vendor="dell"} 1
+`,
+		},
+		{
+			// The linked SmartStorage resource does not exist.
+			name:   "hpe missing smart storage",
+			vendor: "HPE",
+			oem:    hpeOem,
+			names:  []string{"redfish_oem_hpe_aggregate_health", "redfish_collector_scrape_status"},
+			absent: []string{"redfish_oem_hpe_smart_storage_health"},
+			expected: `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="oem"} 0
+# HELP redfish_oem_hpe_aggregate_health health of the iLO AggregateHealthStatus component,1(OK),2(Warning),3(Critical)
+# TYPE redfish_oem_hpe_aggregate_health gauge
+redfish_oem_hpe_aggregate_health{component="biosorhardwarehealth",resource="system",system_id="1"} 1
+redfish_oem_hpe_aggregate_health{component="fans",resource="system",system_id="1"} 1
+redfish_oem_hpe_aggregate_health{component="memory",resource="system",system_id="1"} 2
+redfish_oem_hpe_aggregate_health{component="storage",resource="system",system_id="1"} 1
+`,
+		},
+		{
+			name:   "malformed oem section",
+			vendor: "Dell",
+			oem:    `{"Dell": {"DellSystem": []}}`,
+			names:  []string{"redfish_collector_scrape_status"},
+			absent: []string{"redfish_oem_dell_system_rollup_health"},
+			expected: `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="oem"} 0
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gatherer := newGatherer(t, tc.vendor, tc.oem, tc.resources)
+			if err := testutil.GatherAndCompare(gatherer, strings.NewReader(tc.expected), tc.names...); err != nil {
+				t.Error(err)
+			}
+			for _, name := range tc.absent {
+				if count, err := testutil.GatherAndCount(gatherer, name); err != nil || count != 0 {
+					t.Errorf("expected no %s series, got %d: %v", name, count, err)
+				}
+			}
+		})
+	}
+}
+
+// Services older than Redfish 1.5 have no Vendor, the vendor is detected
+// from the keys of the Oem section of the service root instead.
+func TestCollectorVendorFromOemKeys(t *testing.T) {
+	root := redfishtest.DefaultTree()[redfishtest.ServiceRoot]
+	delete(root, "Vendor")
+	root["Oem"] = redfishtest.Resource{"Lenovo": redfishtest.Resource{"@odata.type": "#LenovoServiceRoot.v1_0_0.LenovoServiceRoot"}}
+	gatherer := newGatherer(t, "", lenovoOem, map[string]redfishtest.Resource{redfishtest.ServiceRoot: root})
+	expected := `
+# HELP redfish_oem_vendor_info vendor of the redfish service as detected from the service root
+# TYPE redfish_oem_vendor_info gauge
+redfish_oem_vendor_info{vendor="lenovo"} 1
+`
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), "redfish_oem_vendor_info"); err != nil {
+		t.Error(err)
+	}
+	if count, err := testutil.GatherAndCount(gatherer, "redfish_oem_lenovo_front_panel_usb_info"); err != nil || count != 1 {
+		t.Errorf("expected the lenovo front panel usb info, got %d: %v", count, err)
+	}
+}
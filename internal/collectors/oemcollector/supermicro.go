@@ -0,0 +1,72 @@
+package oemcollector
+
+import (
+	"fmt"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	supermicroNodeManagerCurrentMetric = "supermicro_node_manager_current"
+	supermicroNodeManagerMinimumMetric = "supermicro_node_manager_minimum"
+	supermicroNodeManagerMaximumMetric = "supermicro_node_manager_maximum"
+	supermicroNodeManagerAverageMetric = "supermicro_node_manager_average"
+)
+
+var (
+	supermicroNodeManagerLabels = []string{"domain_id", "mode"}
+)
+
+func supermicroMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		supermicroNodeManagerCurrentMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, supermicroNodeManagerCurrentMetric),
+			"current value reported by the Intel node manager statistic, watts for power statistics",
+			append(labels, supermicroNodeManagerLabels...),
+			nil,
+		),
+		supermicroNodeManagerMinimumMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, supermicroNodeManagerMinimumMetric),
+			"minimum value reported by the Intel node manager statistic over its reporting period",
+			append(labels, supermicroNodeManagerLabels...),
+			nil,
+		),
+		supermicroNodeManagerMaximumMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, supermicroNodeManagerMaximumMetric),
+			"maximum value reported by the Intel node manager statistic over its reporting period",
+			append(labels, supermicroNodeManagerLabels...),
+			nil,
+		),
+		supermicroNodeManagerAverageMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, supermicroNodeManagerAverageMetric),
+			"average value reported by the Intel node manager statistic over its reporting period",
+			append(labels, supermicroNodeManagerLabels...),
+			nil,
+		),
+	}
+}
+
+func (c *Collector) collectSupermicroNodeManagerMetrics(ch chan<- prometheus.Metric, system *redfish.ComputerSystem) error {
+	c.logger.Debug("Collecting supermicro node manager metrics")
+	nodeManager, err := redfish.SupermicroNodeManager(system)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to get supermicro node manager information for system %s", system.ID), zap.Error(err))
+		return err
+	} else if nodeManager == nil {
+		c.logger.Debug(fmt.Sprintf("No supermicro node manager information for system %s", system.ID))
+		return nil
+	}
+
+	for _, statistic := range nodeManager.Statistics {
+		labelValues := []string{"node_manager", system.ID, statistic.DomainID, statistic.Mode}
+		ch <- prometheus.MustNewConstMetric(c.metrics[supermicroNodeManagerCurrentMetric], prometheus.GaugeValue, float64(statistic.CurrentValue), labelValues...)
+		ch <- prometheus.MustNewConstMetric(c.metrics[supermicroNodeManagerMinimumMetric], prometheus.GaugeValue, float64(statistic.MinimumValue), labelValues...)
+		ch <- prometheus.MustNewConstMetric(c.metrics[supermicroNodeManagerMaximumMetric], prometheus.GaugeValue, float64(statistic.MaximumValue), labelValues...)
+		ch <- prometheus.MustNewConstMetric(c.metrics[supermicroNodeManagerAverageMetric], prometheus.GaugeValue, float64(statistic.AverageValue), labelValues...)
+	}
+
+	return nil
+}
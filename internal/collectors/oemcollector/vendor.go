@@ -0,0 +1,80 @@
+package oemcollector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type vendor string
+
+const (
+	vendorUnknown    vendor = "unknown"
+	vendorDell       vendor = "dell"
+	vendorHPE        vendor = "hpe"
+	vendorLenovo     vendor = "lenovo"
+	vendorSupermicro vendor = "supermicro"
+	vendorGigabyte   vendor = "gigabyte"
+	vendorAMI        vendor = "ami"
+)
+
+// vendorKeys maps the names used in ServiceRoot.Vendor and as keys of Oem
+// sections to the vendor they identify. Keys are lower case.
+var vendorKeys = map[string]vendor{
+	"dell":       vendorDell,
+	"hpe":        vendorHPE,
+	"hp":         vendorHPE,
+	"lenovo":     vendorLenovo,
+	"supermicro": vendorSupermicro,
+	"gigabyte":   vendorGigabyte,
+	"ami":        vendorAMI,
+}
+
+func vendorMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		vendorInfoMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, vendorInfoMetric),
+			"vendor of the redfish service as detected from the service root",
+			[]string{"vendor"},
+			nil,
+		),
+	}
+}
+
+// detectVendor prefers ServiceRoot.Vendor and falls back to the keys of the
+// service root's Oem section for services older than Redfish 1.5.
+func detectVendor(service *redfish.Service) vendor {
+	if v, ok := vendorKeys[strings.ToLower(service.Vendor)]; ok {
+		return v
+	}
+
+	oem := map[string]json.RawMessage{}
+	if err := json.Unmarshal(service.Oem, &oem); err != nil {
+		return vendorUnknown
+	}
+	for key := range oem {
+		if v, ok := vendorKeys[strings.ToLower(key)]; ok {
+			return v
+		}
+	}
+
+	return vendorUnknown
+}
+
+// vendorOem extracts the section of a resource's Oem property that belongs
+// to the given key, e.g. "Dell" or "Hpe".
+func vendorOem(oem json.RawMessage, keys ...string) (json.RawMessage, bool) {
+	sections := map[string]json.RawMessage{}
+	if err := json.Unmarshal(oem, &sections); err != nil {
+		return nil, false
+	}
+	for _, key := range keys {
+		if section, ok := sections[key]; ok {
+			return section, true
+		}
+	}
+	return nil, false
+}
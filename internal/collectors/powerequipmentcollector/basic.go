@@ -0,0 +1,41 @@
+package powerequipmentcollector
+
+import (
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func basicPDUMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		healthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, healthMetric),
+			"pdu",
+			labels,
+		),
+		stateMetric: collectors.NewStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, stateMetric),
+			"pdu",
+			labels,
+		),
+		modelInfoMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, modelInfoMetric),
+			"organization responsible for producing the power distribution unit, its model, part number and firmware version",
+			append(labels, modelLabels...),
+			nil,
+		),
+	}
+}
+
+func (c *Collector) collectBasicMetrics(ch chan<- prometheus.Metric, pdu *powerDistribution) {
+	c.logger.Debug("Collecting basic pdu metrics")
+	labelValues := pdu.labelValues("pdu")
+	if health, ok := collectors.HealthToFloat(pdu.Status.Health); ok {
+		ch <- prometheus.MustNewConstMetric(c.metrics[healthMetric], prometheus.GaugeValue, health, labelValues...)
+	}
+	if state, ok := collectors.StateToFloat(pdu.Status.State); ok {
+		ch <- prometheus.MustNewConstMetric(c.metrics[stateMetric], prometheus.GaugeValue, state, labelValues...)
+	}
+
+	modelValues := []string{pdu.Manufacturer, pdu.Model, pdu.PartNumber, pdu.FirmwareVersion}
+	ch <- prometheus.MustNewConstMetric(c.metrics[modelInfoMetric], prometheus.GaugeValue, 1, append(labelValues, modelValues...)...)
+}
@@ -0,0 +1,103 @@
+package powerequipmentcollector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	circuitStateMetric        = "circuit_state"
+	circuitHealthMetric       = "circuit_health"
+	circuitBreakerStateMetric = "circuit_breaker_state"
+	circuitCurrentAmpsMetric  = "circuit_current_amps"
+	circuitVoltageVoltsMetric = "circuit_voltage_volts"
+	circuitPowerWattsMetric   = "circuit_power_watts"
+	circuitEnergyKWhMetric    = "circuit_energy_kwh"
+)
+
+var (
+	circuitLabels = []string{"circuit", "circuit_id", "circuit_type"}
+)
+
+func circuitMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		circuitStateMetric: collectors.NewStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitStateMetric),
+			"pdu.circuit",
+			append(labels, circuitLabels...),
+		),
+		circuitHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitHealthMetric),
+			"pdu.circuit",
+			append(labels, circuitLabels...),
+		),
+		circuitBreakerStateMetric: collectors.NewBreakerStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitBreakerStateMetric),
+			"pdu.circuit",
+			append(labels, circuitLabels...),
+		),
+		circuitCurrentAmpsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitCurrentAmpsMetric),
+			"Current drawn through the circuit in amps",
+			append(labels, circuitLabels...),
+			nil,
+		),
+		circuitVoltageVoltsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitVoltageVoltsMetric),
+			"Voltage of the circuit in volts",
+			append(labels, circuitLabels...),
+			nil,
+		),
+		circuitPowerWattsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitPowerWattsMetric),
+			"Power drawn through the circuit in watts",
+			append(labels, circuitLabels...),
+			nil,
+		),
+		circuitEnergyKWhMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, circuitEnergyKWhMetric),
+			"Energy consumed through the circuit in kilowatt hours",
+			append(labels, circuitLabels...),
+			nil,
+		),
+	}
+}
+
+func (c *Collector) collectCircuitMetrics(ch chan<- prometheus.Metric, pdu *powerDistribution) {
+	c.logger.Debug("Collecting circuit metrics")
+	for _, property := range []string{"Mains", "Branches"} {
+		circuits, err := redfish.GetCircuits(pdu.PowerDistribution, property)
+		if err != nil {
+			c.logger.Error(fmt.Sprintf("Failed to get %s circuit information for pdu %s", strings.ToLower(property), pdu.ID), zap.Error(err))
+			continue
+		}
+
+		for _, circuit := range circuits {
+			labelValues := append(pdu.labelValues("circuit"), circuit.Name, circuit.ID, strings.ToLower(string(circuit.CircuitType)))
+			if health, ok := collectors.HealthToFloat(circuit.Status.Health); ok {
+				ch <- prometheus.MustNewConstMetric(c.metrics[circuitHealthMetric], prometheus.GaugeValue, health, labelValues...)
+			}
+			if state, ok := collectors.StateToFloat(circuit.Status.State); ok {
+				ch <- prometheus.MustNewConstMetric(c.metrics[circuitStateMetric], prometheus.GaugeValue, state, labelValues...)
+			}
+			if breakerState, ok := collectors.BreakerStateToFloat(circuit.BreakerState); ok {
+				ch <- prometheus.MustNewConstMetric(c.metrics[circuitBreakerStateMetric], prometheus.GaugeValue, breakerState, labelValues...)
+			}
+
+			if c.skipReadings(circuit.Status.State) {
+				continue
+			}
+			c.collectReadings(ch, circuit.Readings, []sensorReading{
+				{circuitCurrentAmpsMetric, "CurrentAmps.Reading", prometheus.GaugeValue},
+				{circuitVoltageVoltsMetric, "Voltage.Reading", prometheus.GaugeValue},
+				{circuitPowerWattsMetric, "PowerWatts.Reading", prometheus.GaugeValue},
+				{circuitEnergyKWhMetric, "EnergykWh.Reading", prometheus.CounterValue},
+			}, labelValues)
+		}
+	}
+}
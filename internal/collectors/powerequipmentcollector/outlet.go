@@ -0,0 +1,103 @@
+package powerequipmentcollector
+
+import (
+	"fmt"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	outletStateMetric        = "outlet_state"
+	outletHealthMetric       = "outlet_health"
+	outletPowerStateMetric   = "outlet_power_state"
+	outletCurrentAmpsMetric  = "outlet_current_amps"
+	outletVoltageVoltsMetric = "outlet_voltage_volts"
+	outletPowerWattsMetric   = "outlet_power_watts"
+	outletEnergyKWhMetric    = "outlet_energy_kwh"
+)
+
+var (
+	outletLabels = []string{"outlet", "outlet_id", "outlet_user_label"}
+)
+
+func outletMetrics() map[string]*prometheus.Desc {
+	return map[string]*prometheus.Desc{
+		outletStateMetric: collectors.NewStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletStateMetric),
+			"pdu.outlet",
+			append(labels, outletLabels...),
+		),
+		outletHealthMetric: collectors.NewHealthDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletHealthMetric),
+			"pdu.outlet",
+			append(labels, outletLabels...),
+		),
+		outletPowerStateMetric: collectors.NewPowerStateDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletPowerStateMetric),
+			"pdu.outlet",
+			append(labels, outletLabels...),
+		),
+		outletCurrentAmpsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletCurrentAmpsMetric),
+			"Current drawn through the outlet in amps",
+			append(labels, outletLabels...),
+			nil,
+		),
+		outletVoltageVoltsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletVoltageVoltsMetric),
+			"Voltage of the outlet in volts",
+			append(labels, outletLabels...),
+			nil,
+		),
+		outletPowerWattsMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletPowerWattsMetric),
+			"Power drawn through the outlet in watts",
+			append(labels, outletLabels...),
+			nil,
+		),
+		outletEnergyKWhMetric: prometheus.NewDesc(
+			prometheus.BuildFQName(collectors.Namespace, subsystem, outletEnergyKWhMetric),
+			"Energy consumed through the outlet in kilowatt hours",
+			append(labels, outletLabels...),
+			nil,
+		),
+	}
+}
+
+func (c *Collector) collectOutletMetrics(ch chan<- prometheus.Metric, pdu *powerDistribution) {
+	c.logger.Debug("Collecting outlet metrics")
+	outlets, err := redfish.GetOutlets(pdu.PowerDistribution)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("Failed to get outlet information for pdu %s", pdu.ID), zap.Error(err))
+		return
+	} else if len(outlets) == 0 {
+		c.logger.Warn(fmt.Sprintf("No outlet information for pdu %s", pdu.ID))
+		return
+	}
+
+	for _, outlet := range outlets {
+		labelValues := append(pdu.labelValues("outlet"), outlet.Name, outlet.ID, outlet.UserLabel)
+		if health, ok := collectors.HealthToFloat(outlet.Status.Health); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[outletHealthMetric], prometheus.GaugeValue, health, labelValues...)
+		}
+		if state, ok := collectors.StateToFloat(outlet.Status.State); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[outletStateMetric], prometheus.GaugeValue, state, labelValues...)
+		}
+		if powerState, ok := collectors.PowerStateToFloat(outlet.PowerState); ok {
+			ch <- prometheus.MustNewConstMetric(c.metrics[outletPowerStateMetric], prometheus.GaugeValue, powerState, labelValues...)
+		}
+
+		if c.skipReadings(outlet.Status.State) {
+			continue
+		}
+		c.collectReadings(ch, outlet.Readings, []sensorReading{
+			{outletCurrentAmpsMetric, "CurrentAmps.Reading", prometheus.GaugeValue},
+			{outletVoltageVoltsMetric, "Voltage.Reading", prometheus.GaugeValue},
+			{outletPowerWattsMetric, "PowerWatts.Reading", prometheus.GaugeValue},
+			{outletEnergyKWhMetric, "EnergykWh.Reading", prometheus.CounterValue},
+		}, labelValues)
+	}
+}
@@ -0,0 +1,189 @@
+package powerequipmentcollector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const (
+	subsystem = "pdu"
+)
+
+var (
+	// equipment_type tells apart units of different kinds, which may share
+	// an Id.
+	labels          = []string{"resource", "pdu_id", "equipment_type"}
+	modelLabels     = []string{"manufacturer", "model", "part_number", "firmware_version"}
+	healthMetric    = "health"
+	stateMetric     = "state"
+	modelInfoMetric = "model_info"
+)
+
+type collectorFunc func(chan<- prometheus.Metric, *powerDistribution)
+
+// powerDistribution is a power distribution unit and the kind of equipment it
+// was listed as, e.g. rack_pdu.
+type powerDistribution struct {
+	*redfish.PowerDistribution
+	equipmentType string
+}
+
+// labelValues returns the values of labels for resource of pdu.
+func (pdu *powerDistribution) labelValues(resource string) []string {
+	return []string{resource, pdu.ID, pdu.equipmentType}
+}
+
+// sensorReading is a reading of a sensor exported as metric.
+type sensorReading struct {
+	metric    string
+	property  string
+	valueType prometheus.ValueType
+}
+
+type Collector struct {
+	logger             *log.Logger
+	redfish            *redfish.Client
+	metrics            map[string]*prometheus.Desc
+	scrapeStatus       *collectors.ScrapeStatus
+	tracer             trace.Tracer
+	legacyNullReadings bool
+	collectorFuncs     []collectorFunc
+}
+
+func New(logger *log.Logger, client *redfish.Client, cfg config.Config, scrapeStatus *collectors.ScrapeStatus, tracer trace.Tracer) *Collector {
+	return &Collector{
+		logger:             logger,
+		redfish:            client,
+		metrics:            make(map[string]*prometheus.Desc),
+		scrapeStatus:       scrapeStatus,
+		tracer:             tracer,
+		legacyNullReadings: cfg.Metrics.LegacyNullReadings,
+	}
+}
+
+func Register(collector *Collector, registry *collectors.Registry, cfg config.Config, lc fx.Lifecycle) {
+	if !cfg.Collectors.PowerEquipment {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			metricGroups := []map[string]*prometheus.Desc{
+				basicPDUMetrics(),
+				outletMetrics(),
+				circuitMetrics(),
+			}
+			for _, metrics := range metricGroups {
+				for metricName, metric := range metrics {
+					collector.metrics[metricName] = metric
+				}
+			}
+			collector.collectorFuncs = []collectorFunc{
+				collector.collectBasicMetrics,
+				collector.collectOutletMetrics,
+				collector.collectCircuitMetrics,
+			}
+
+			return registry.Register(collector)
+		},
+	})
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+func (c *Collector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Debug("Collecting power equipment metrics")
+	ctx, span := c.tracer.Start(ctx, "power_equipment")
+	defer span.End()
+
+	client, err := c.redfish.WithContext(ctx)
+	if err != nil {
+		c.logger.Error("Failed to connect to redfish service", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("power_equipment").Set(float64(0))
+		return
+	}
+
+	equipment, err := client.GetService().PowerEquipment()
+	if err != nil {
+		c.logger.Error("Failed to get power equipment", log.Error(err))
+		tracing.Fail(span, err)
+		c.scrapeStatus.WithLabelValues("power_equipment").Set(float64(0))
+		return
+	}
+
+	pdus := []*powerDistribution{}
+	listFuncs := map[string]func() ([]*redfish.PowerDistribution, error){
+		"rack_pdu":    equipment.RackPDUs,
+		"floor_pdu":   equipment.FloorPDUs,
+		"power_shelf": equipment.PowerShelves,
+	}
+	failed := false
+	for kind, list := range listFuncs {
+		units, err := list()
+		if err != nil {
+			c.logger.Error(fmt.Sprintf("Failed to get %s power distribution units", kind), log.Error(err))
+			tracing.Fail(span, err)
+			failed = true
+			continue
+		}
+		for _, unit := range units {
+			pdus = append(pdus, &powerDistribution{PowerDistribution: unit, equipmentType: kind})
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for _, pdu := range pdus {
+		for _, collectorFunc := range c.collectorFuncs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				collectorFunc(ch, pdu)
+			}()
+		}
+	}
+	wg.Wait()
+
+	c.logger.Debug("Finished collecting power equipment metrics")
+	if failed {
+		c.scrapeStatus.WithLabelValues("power_equipment").Set(float64(0))
+		return
+	}
+	c.scrapeStatus.WithLabelValues("power_equipment").Set(float64(1))
+}
+
+// collectReadings exports the sensor readings the BMC reported, or all of
+// them as 0 if null readings are exported like earlier versions did.
+func (c *Collector) collectReadings(ch chan<- prometheus.Metric, readings redfish.Readings, sensorReadings []sensorReading, labelValues []string) {
+	for _, reading := range sensorReadings {
+		value, ok := readings.Get(reading.property)
+		if !ok && !c.legacyNullReadings {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics[reading.metric], reading.valueType, value, labelValues...)
+	}
+}
+
+// skipReadings reports whether the readings of a sensor in state are
+// meaningless, because the sensor is absent.
+func (c *Collector) skipReadings(state redfish.State) bool {
+	return state == redfish.StateAbsent && !c.legacyNullReadings
+}
@@ -0,0 +1,118 @@
+package powerequipmentcollector_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/collectors/powerequipmentcollector"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const (
+	powerEquipmentURI = "/redfish/v1/PowerEquipment"
+	powerShelvesURI   = powerEquipmentURI + "/PowerShelves"
+	powerShelfURI     = powerShelvesURI + "/1"
+)
+
+// newGatherer serves the rack PDU mockup with a power shelf that has the
+// same Id as the rack PDU, modified by setup.
+func newGatherer(t *testing.T, setup func(*redfishtest.Server)) prometheus.Gatherer {
+	t.Helper()
+
+	resources, err := redfishtest.LoadFixture("../testdata/mockups/rackpdu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources[powerEquipmentURI]["PowerShelves"] = redfishtest.Link(powerShelvesURI)
+	resources[powerShelvesURI] = redfishtest.Collection(powerShelvesURI, powerShelfURI)
+	resources[powerShelfURI] = redfishtest.Resource{
+		"@odata.id":     powerShelfURI,
+		"Id":            "1",
+		"Name":          "Power Shelf",
+		"EquipmentType": "PowerShelf",
+		"Status":        redfishtest.Resource{"State": "Enabled", "Health": "Warning"},
+	}
+
+	server := redfishtest.NewServer(resources)
+	t.Cleanup(server.Close)
+	if setup != nil {
+		setup(server)
+	}
+
+	cfg := config.Config{
+		Host: config.Host{
+			Endpoint: server.URL,
+			Username: server.Username,
+			Password: server.Password,
+		},
+		Collectors: config.Collectors{PowerEquipment: true},
+	}
+	tracer := noop.NewTracerProvider().Tracer("")
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := collectors.NewRegistry()
+	scrapeStatus := collectors.NewScrapeStatus()
+	lc := fxtest.NewLifecycle(t)
+	collectors.RegisterScrapeStatus(scrapeStatus, registry, lc)
+	powerequipmentcollector.Register(powerequipmentcollector.New(logger, client, cfg, scrapeStatus, tracer), registry, cfg, lc)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return registry.GatherContext(context.Background())
+	})
+}
+
+func TestCollectorEquipmentTypes(t *testing.T) {
+	gatherer := newGatherer(t, nil)
+	expected := `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="power_equipment"} 1
+# HELP redfish_pdu_health health of pdu,1(OK),2(Warning),3(Critical)
+# TYPE redfish_pdu_health gauge
+redfish_pdu_health{equipment_type="power_shelf",pdu_id="1",resource="pdu"} 2
+redfish_pdu_health{equipment_type="rack_pdu",pdu_id="1",resource="pdu"} 1
+`
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), "redfish_pdu_health", "redfish_collector_scrape_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorPartialFailure(t *testing.T) {
+	gatherer := newGatherer(t, func(s *redfishtest.Server) {
+		s.Fault(powerShelvesURI, redfishtest.Fault{Status: http.StatusInternalServerError})
+	})
+	// The rack PDU is still exported.
+	expected := `
+# HELP redfish_collector_scrape_status collector_scrape_status
+# TYPE redfish_collector_scrape_status gauge
+redfish_collector_scrape_status{collector="power_equipment"} 0
+# HELP redfish_pdu_health health of pdu,1(OK),2(Warning),3(Critical)
+# TYPE redfish_pdu_health gauge
+redfish_pdu_health{equipment_type="rack_pdu",pdu_id="1",resource="pdu"} 1
+`
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected), "redfish_pdu_health", "redfish_collector_scrape_status"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,98 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ContextCollector is implemented by collectors that talk to the BMC. The
+// context passed to CollectContext bounds every Redfish request made while
+// collecting.
+type ContextCollector interface {
+	prometheus.Collector
+	CollectContext(ctx context.Context, ch chan<- prometheus.Metric)
+}
+
+// Registry is the registry redfish collectors register with. Besides the
+// plain prometheus.Registry it remembers the registered collectors so they
+// can be gathered with a per-scrape context.
+type Registry struct {
+	*prometheus.Registry
+
+	mu         sync.Mutex
+	collectors []prometheus.Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		Registry: prometheus.NewRegistry(),
+	}
+}
+
+func (r *Registry) Register(c prometheus.Collector) error {
+	if err := r.Registry.Register(c); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+// GatherContext gathers every registered collector with ctx and reports
+// whether its deadline was hit in redfish_scrape_timeout.
+func (r *Registry) GatherContext(ctx context.Context) ([]*dto.MetricFamily, error) {
+	scrape := prometheus.NewRegistry()
+	// Collectors that do not talk to the BMC, such as the scrape status, are
+	// gathered only after the scrape so they reflect its outcome.
+	after := prometheus.NewRegistry()
+	after.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "scrape_timeout",
+			Help:      "1 if the scrape deadline was reached before all collectors finished and only the completed metrics were returned",
+		},
+		func() float64 {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return 1
+			}
+			return 0
+		},
+	))
+
+	r.mu.Lock()
+	for _, c := range r.collectors {
+		var err error
+		if cc, ok := c.(ContextCollector); ok {
+			err = scrape.Register(&contextCollector{ctx: ctx, collector: cc})
+		} else {
+			err = after.Register(c)
+		}
+		if err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+	}
+	r.mu.Unlock()
+
+	// Gatherers are gathered in order.
+	return prometheus.Gatherers{scrape, after}.Gather()
+}
+
+type contextCollector struct {
+	ctx       context.Context
+	collector ContextCollector
+}
+
+func (c *contextCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+func (c *contextCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collector.CollectContext(c.ctx, ch)
+}
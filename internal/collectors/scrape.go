@@ -0,0 +1,35 @@
+package collectors
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+// ScrapeStatus is shared between all collectors so that each one reports
+// its outcome under the same metric family.
+type ScrapeStatus struct {
+	*prometheus.GaugeVec
+}
+
+func NewScrapeStatus() *ScrapeStatus {
+	return &ScrapeStatus{
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Name:      "collector_scrape_status",
+				Help:      "collector_scrape_status",
+			},
+			[]string{"collector"},
+		),
+	}
+}
+
+func RegisterScrapeStatus(status *ScrapeStatus, registry *Registry, lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return registry.Register(status)
+		},
+	})
+}
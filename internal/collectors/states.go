@@ -45,55 +45,30 @@ func StateToFloat(state redfish.State) (float64, bool) {
 	return float64(0), false
 }
 
-//func parseCommonPowerState(status redfish.PowerState) (float64, bool) {
-//	if bytes.Equal([]byte(status), []byte("On")) {
-//		return float64(1), true
-//	} else if bytes.Equal([]byte(status), []byte("Off")) {
-//		return float64(2), true
-//	} else if bytes.Equal([]byte(status), []byte("PoweringOn")) {
-//		return float64(3), true
-//	} else if bytes.Equal([]byte(status), []byte("PoweringOff")) {
-//		return float64(4), true
-//	}
-//	return float64(0), false
-//}
-//
-//func parseLinkStatus(status redfish.LinkStatus) (float64, bool) {
-//	if bytes.Equal([]byte(status), []byte("LinkUp")) {
-//		return float64(1), true
-//	} else if bytes.Equal([]byte(status), []byte("NoLink")) {
-//		return float64(2), true
-//	} else if bytes.Equal([]byte(status), []byte("LinkDown")) {
-//		return float64(3), true
-//	}
-//	return float64(0), false
-//}
-//
-//func parsePortLinkStatus(status redfish.NetworkPortLinkStatus) (float64, bool) {
-//	if bytes.Equal([]byte(status), []byte("Up")) {
-//		return float64(1), true
-//	}
-//	return float64(0), false
-//}
-//func boolToFloat64(data bool) float64 {
-//
-//	if data {
-//		return float64(1)
-//	}
-//	return float64(0)
-//
-//}
-//
-//func parsePhySecIntrusionSensor(method redfish.IntrusionSensor) (float64, bool) {
-//	if bytes.Equal([]byte(method), []byte("Normal")) {
-//		return float64(1), true
-//	}
-//	if bytes.Equal([]byte(method), []byte("TamperingDetected")) {
-//		return float64(2), true
-//	}
-//	if bytes.Equal([]byte(method), []byte("HardwareIntrusion")) {
-//		return float64(3), true
-//	}
-//
-//	return float64(0), false
-//}
+func PowerStateToFloat(state redfish.PowerState) (float64, bool) {
+	switch state {
+	case redfish.PowerStateOn:
+		return float64(1), true
+	case redfish.PowerStateOff:
+		return float64(2), true
+	case redfish.PowerStatePoweringOn:
+		return float64(3), true
+	case redfish.PowerStatePoweringOff:
+		return float64(4), true
+	case redfish.PowerStatePaused:
+		return float64(5), true
+	}
+	return float64(0), false
+}
+
+func BreakerStateToFloat(state redfish.BreakerState) (float64, bool) {
+	switch state {
+	case redfish.BreakerStateNormal:
+		return float64(1), true
+	case redfish.BreakerStateTripped:
+		return float64(2), true
+	case redfish.BreakerStateOff:
+		return float64(3), true
+	}
+	return float64(0), false
+}
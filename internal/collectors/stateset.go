@@ -0,0 +1,112 @@
+package collectors
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+const stateSetLabel = "state"
+
+// The states of the enum metrics, in the order of their numeric values.
+var (
+	healthStates = []string{"OK", "Warning", "Critical"}
+	commonStates = []string{"Enabled", "Disabled", "StandbyOffline", "StandbySpare", "InTest", "Starting",
+		"Absent", "UnavailableOffline", "Deferring", "Quiesced", "Updating"}
+	powerStates   = []string{"On", "Off", "PoweringOn", "PoweringOff", "Paused"}
+	breakerStates = []string{"Normal", "Tripped", "Off"}
+)
+
+type enumFamily struct {
+	// help is the help text without the documentation of the values.
+	help   string
+	states []string
+}
+
+// enumFamilies are the families of the enum metrics keyed by name. They are
+// added by the New*Desc functions, so only the metrics of the built-in
+// collectors are encoded as state sets, whatever the help text of a custom
+// metric says.
+var (
+	enumFamiliesMu sync.RWMutex
+	enumFamilies   = make(map[string]enumFamily)
+)
+
+// newEnumDesc returns the descriptor of an enum metric whose help text
+// documents the numeric values with valuesHelp.
+func newEnumDesc(fqName, help, valuesHelp string, states []string, variableLabels []string) *prometheus.Desc {
+	enumFamiliesMu.Lock()
+	enumFamilies[fqName] = enumFamily{help: help, states: states}
+	enumFamiliesMu.Unlock()
+	return prometheus.NewDesc(fqName, help+","+valuesHelp, variableLabels, nil)
+}
+
+// StateSetGatherer encodes the health and state metrics gathered by g as
+// state sets instead of numbers: one series per state, labelled state, which
+// is 1 for the current state and 0 for the others, e.g.
+// redfish_chassis_health{state="Warning"} 1.
+func StateSetGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		// The families may be a cached snapshot, so they are not modified.
+		stateSets := make([]*dto.MetricFamily, 0, len(families))
+		for _, family := range families {
+			stateSets = append(stateSets, toStateSet(family))
+		}
+		return stateSets, err
+	})
+}
+
+func toStateSet(family *dto.MetricFamily) *dto.MetricFamily {
+	enumFamiliesMu.RLock()
+	enum, ok := enumFamilies[family.GetName()]
+	enumFamiliesMu.RUnlock()
+	if !ok || family.GetType() != dto.MetricType_GAUGE || hasLabel(family, stateSetLabel) {
+		return family
+	}
+
+	stateSet := &dto.MetricFamily{
+		Name: family.Name,
+		Help: proto.String(enum.help),
+		Type: family.Type,
+	}
+	for _, metric := range family.Metric {
+		value := int(metric.GetGauge().GetValue())
+		// Labels are sorted by name, as in the families gathered by a
+		// registry.
+		at := sort.Search(len(metric.Label), func(i int) bool {
+			return metric.Label[i].GetName() > stateSetLabel
+		})
+		for i, state := range enum.states {
+			current := 0.0
+			if value == i+1 {
+				current = 1
+			}
+
+			labels := make([]*dto.LabelPair, 0, len(metric.Label)+1)
+			labels = append(labels, metric.Label[:at]...)
+			labels = append(labels, &dto.LabelPair{Name: proto.String(stateSetLabel), Value: proto.String(state)})
+			labels = append(labels, metric.Label[at:]...)
+			stateSet.Metric = append(stateSet.Metric, &dto.Metric{
+				Label:       labels,
+				Gauge:       &dto.Gauge{Value: proto.Float64(current)},
+				TimestampMs: metric.TimestampMs,
+			})
+		}
+	}
+	return stateSet
+}
+
+func hasLabel(family *dto.MetricFamily, name string) bool {
+	for _, metric := range family.Metric {
+		for _, label := range metric.Label {
+			if label.GetName() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// threshold is a threshold property that Redfish sensors carry next to
+// their reading, in the same unit.
+type threshold struct {
+	property string
+	suffix   string
+	help     string
+}
+
+var thresholds = []threshold{
+	{"LowerThresholdNonCritical", "lower_threshold_non_critical", "threshold below the normal range that is not considered critical"},
+	{"LowerThresholdCritical", "lower_threshold_critical", "threshold below the normal range that is not considered fatal"},
+	{"LowerThresholdFatal", "lower_threshold_fatal", "threshold below the normal range that is considered fatal"},
+	{"UpperThresholdNonCritical", "upper_threshold_non_critical", "threshold above the normal range that is not considered critical"},
+	{"UpperThresholdCritical", "upper_threshold_critical", "threshold above the normal range that is not considered fatal"},
+	{"UpperThresholdFatal", "upper_threshold_fatal", "threshold above the normal range that is considered fatal"},
+}
+
+// ThresholdMetrics describes the thresholds of a sensor whose reading is
+// exported as reading, e.g. temperature_celsius_upper_threshold_critical.
+func ThresholdMetrics(subsystem, reading string, labels []string) map[string]*prometheus.Desc {
+	metrics := make(map[string]*prometheus.Desc, len(thresholds))
+	for _, t := range thresholds {
+		name := reading + "_" + t.suffix
+		metrics[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, name),
+			t.help,
+			labels,
+			nil,
+		)
+	}
+	return metrics
+}
+
+// CollectThresholds exports the thresholds of a sensor described by
+// ThresholdMetrics. value returns a threshold property of the sensor and
+// whether it should be exported.
+func CollectThresholds(ch chan<- prometheus.Metric, metrics map[string]*prometheus.Desc, reading string, value func(property string) (float64, bool), labelValues ...string) {
+	for _, t := range thresholds {
+		if v, ok := value(t.property); ok {
+			ch <- prometheus.MustNewConstMetric(metrics[reading+"_"+t.suffix], prometheus.GaugeValue, v, labelValues...)
+		}
+	}
+}
@@ -2,16 +2,24 @@ package config
 
 import (
 	"fmt"
-	"strings"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Host     Host    `mapstructure:"host"`
-	LogLevel string  `mapstructure:"logLevel"`
-	Metrics  Metrics `mapstructure:"metrics"`
-	Web      Web     `mapstructure:"web"`
+	Host          Host           `mapstructure:"host"`
+	LogLevel      string         `mapstructure:"logLevel"`
+	Metrics       Metrics        `mapstructure:"metrics"`
+	Collectors    Collectors     `mapstructure:"collectors"`
+	CustomMetrics []CustomMetric `mapstructure:"customMetrics"`
+	Events        Events         `mapstructure:"events"`
+	Polling       Polling        `mapstructure:"polling"`
+	Scrape        Scrape         `mapstructure:"scrape"`
+	Tracing       Tracing        `mapstructure:"tracing"`
+	Web           Web            `mapstructure:"web"`
 }
 
 type Web struct {
@@ -24,16 +32,163 @@ func (w Web) ListenAddress() string {
 	return fmt.Sprintf("%s:%d", w.Address, w.Port)
 }
 
+// Polling configures background collection. When enabled the BMC is polled
+// on its own interval and scrapes are answered from the last complete poll.
+type Polling struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	// StaleAfter is how long the last complete poll is served after polls
+	// start failing. Defaults to three intervals.
+	StaleAfter time.Duration `mapstructure:"staleAfter"`
+}
+
+// Scrape bounds how long a scrape may spend talking to the BMC and how many
+// scrapes may do so at once.
+type Scrape struct {
+	// Timeout is used when the scraper does not send
+	// X-Prometheus-Scrape-Timeout-Seconds. Zero disables the deadline.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// TimeoutOffset is subtracted from the timeout to leave time for
+	// encoding and sending the response.
+	TimeoutOffset time.Duration `mapstructure:"timeoutOffset"`
+	// MaxConcurrent limits the scrapes walking the BMC at the same time.
+	// Zero disables the limit.
+	MaxConcurrent int `mapstructure:"maxConcurrent"`
+	// MaxQueue is the number of scrapes that may wait for a free worker.
+	MaxQueue int `mapstructure:"maxQueue"`
+	// RejectPolicy decides how scrapes beyond the queue are answered:
+	// "reject" responds with 429, "stale" serves the last completed scrape.
+	RejectPolicy string `mapstructure:"rejectPolicy"`
+}
+
+// Events counts the events of the BMC. They are either pushed by the BMC to
+// /events on the exporter, or read from the Server-Sent Events stream of the
+// BMC.
+type Events struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Mode is "push", which subscribes to the EventService of the BMC, or
+	// "sse", which keeps a stream open to the BMC and does not need the BMC
+	// to connect to the exporter.
+	Mode string `mapstructure:"mode"`
+	// Destination is the URL of /events as reached by the BMC in push mode,
+	// e.g. https://exporter.example.com:9610/events. Most BMCs require
+	// https.
+	Destination string `mapstructure:"destination"`
+	// Log forwards every event to the log.
+	Log bool `mapstructure:"log"`
+}
+
+// Tracing exports spans of scrapes and of the requests sent to the BMC over
+// OTLP. It is disabled unless Endpoint is set.
+type Tracing struct {
+	// Endpoint of the OTLP receiver, e.g. localhost:4317 for grpc or
+	// localhost:4318 for http.
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol is either "grpc" or "http".
+	Protocol string `mapstructure:"protocol"`
+	// Insecure sends spans without TLS.
+	Insecure bool `mapstructure:"insecure"`
+	// SampleRatio is the fraction of scrapes that are traced.
+	SampleRatio float64 `mapstructure:"sampleRatio"`
+}
+
 type Host struct {
-	Endpoint  string `mapstructure:"endpoint"`
-	Username  string `mapstructure:"username"`
-	Password  string `mapstructure:"password"`
-	BasicAuth bool   `mapstructure:"basicAuth"`
+	Endpoint string `mapstructure:"endpoint"`
+	// Username and Password may reference environment variables as
+	// ${NAME}.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// UsernameFile and PasswordFile take precedence over Username and
+	// Password and are read again whenever a session is created.
+	UsernameFile string `mapstructure:"usernameFile"`
+	PasswordFile string `mapstructure:"passwordFile"`
+	BasicAuth    bool   `mapstructure:"basicAuth"`
+	// MaxConcurrentRequests limits the requests in flight to the BMC.
+	MaxConcurrentRequests int `mapstructure:"maxConcurrentRequests"`
+	// RequestsPerSecond optionally limits the request rate to the BMC.
+	RequestsPerSecond float64 `mapstructure:"requestsPerSecond"`
+	TLS               TLS     `mapstructure:"tls"`
+}
+
+// TLS configures how the certificate of the BMC is verified.
+type TLS struct {
+	// InsecureSkipVerify disables certificate verification entirely.
+	InsecureSkipVerify bool `mapstructure:"insecureSkipVerify"`
+	// CAFile is a PEM bundle used instead of the system roots.
+	CAFile string `mapstructure:"caFile"`
+	// ServerName overrides the name the certificate is verified against.
+	ServerName string `mapstructure:"serverName"`
+	// PinnedSHA256 lists SHA-256 fingerprints of accepted certificates.
+	// When set, only these certificates are accepted and the chain is not
+	// verified, which allows self-signed certificates.
+	PinnedSHA256 []string `mapstructure:"pinnedSHA256"`
+	// MinVersion and MaxVersion bound the TLS version, e.g. "1.2".
+	MinVersion string `mapstructure:"minVersion"`
+	MaxVersion string `mapstructure:"maxVersion"`
+	// CertFile and KeyFile hold a client certificate used to authenticate
+	// to the BMC instead of a username and password. Both files are
+	// reloaded when they change.
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+	// LegacyCiphers allows TLS 1.0 and weak cipher suites such as RC4 and
+	// 3DES for old BMCs that support nothing else.
+	LegacyCiphers bool `mapstructure:"legacyCiphers"`
+}
+
+type Collectors struct {
+	// PowerEquipment enables the collector for rack PDUs, power shelves and
+	// other equipment found under /redfish/v1/PowerEquipment.
+	PowerEquipment bool `mapstructure:"powerEquipment"`
+	// OEM enables vendor specific collectors for data that is only
+	// available in Oem sections, selected by the detected vendor.
+	OEM bool `mapstructure:"oem"`
+}
+
+// CustomMetric declares a metric that is read from an arbitrary Redfish
+// resource without requiring a dedicated collector.
+type CustomMetric struct {
+	// Name of the metric, prefixed with the exporter namespace.
+	Name string `mapstructure:"name"`
+	Help string `mapstructure:"help"`
+	// Type is either gauge (default) or counter.
+	Type string `mapstructure:"type"`
+	// URI of the resource. A * segment expands to every member of the
+	// collection at the preceding path.
+	URI string `mapstructure:"uri"`
+	// Items is an optional path selecting the elements within the resource
+	// that each produce a series, e.g. PowerControl[*].
+	Items string `mapstructure:"items"`
+	// Value is the path to the value, relative to each item.
+	Value string `mapstructure:"value"`
+	// Labels are read from paths relative to each item, or to the resource
+	// when the path starts with $. They are a list rather than a map because
+	// viper lower cases map keys.
+	Labels []CustomLabel `mapstructure:"labels"`
+	// ValueMap translates string values such as enums to numbers.
+	ValueMap map[string]float64 `mapstructure:"valueMap"`
+}
+
+type CustomLabel struct {
+	Name string `mapstructure:"name"`
+	Path string `mapstructure:"path"`
 }
 
 type Metrics struct {
 	EnableAll bool              `mapstructure:"enableAll"`
 	Metrics   map[string]Metric `mapstructure:"metrics"`
+	// StateEncoding selects how health and state metrics are exposed:
+	// "numeric" as a single series with the value documented in the help,
+	// "stateset" as one series per state labelled state, which is 1 for the
+	// current state.
+	StateEncoding string `mapstructure:"stateEncoding"`
+	// Naming selects the metric naming scheme: "legacy", "v2" with base
+	// units and without the redundant resource label, or "both" to expose
+	// renamed metrics under both names while migrating.
+	Naming string `mapstructure:"naming"`
+	// LegacyNullReadings exports readings and thresholds the BMC reports as
+	// null, and readings of absent sensors, as 0 like earlier versions did
+	// instead of skipping them.
+	LegacyNullReadings bool `mapstructure:"legacyNullReadings"`
 }
 
 type Metric struct {
@@ -41,30 +196,89 @@ type Metric struct {
 	Labels  map[string]string `mapstructure:"labels"`
 }
 
+const defaultFilePath = "./config.yaml"
+
 func New(opts []Option) (Config, error) {
+	config, problems, err := Check(opts)
+	if err != nil {
+		return config, err
+	}
+	if len(problems) > 0 {
+		return config, problems
+	}
+	return config, nil
+}
+
+// Check loads the config like New, but reports every problem found instead
+// of failing, with the line it occurs on in the config file where possible.
+// Keys that do not exist in the config are problems as well. The validators
+// run once the config passes Validate. The error is only set when the config
+// cannot be loaded at all.
+func Check(opts []Option, validators ...func(Config) Problems) (Config, Problems, error) {
 	config := Config{}
 
 	v := viper.New()
 	v.SetDefault("logLevel", "info")
 	v.SetDefault("web.address", "")
 	v.SetDefault("web.port", 9610)
+	v.SetDefault("host.maxConcurrentRequests", 4)
+	v.SetDefault("host.requestsPerSecond", 0)
+	v.SetDefault("polling.enabled", false)
+	v.SetDefault("polling.interval", time.Minute)
+	v.SetDefault("scrape.timeout", 0)
+	v.SetDefault("scrape.timeoutOffset", 500*time.Millisecond)
+	v.SetDefault("scrape.maxConcurrent", 0)
+	v.SetDefault("scrape.maxQueue", 10)
+	v.SetDefault("scrape.rejectPolicy", "reject")
+	v.SetDefault("metrics.stateEncoding", "numeric")
+	v.SetDefault("metrics.naming", "legacy")
+	v.SetDefault("events.mode", "push")
+	v.SetDefault("tracing.protocol", "grpc")
+	v.SetDefault("tracing.sampleRatio", 1)
 
 	v.SetConfigType("yaml")
-	v.SetConfigFile("./config.yaml")
-
-	v.SetEnvPrefix("REDFISH_EXPORTER")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	bindEnv(v)
 
 	for _, opt := range opts {
 		opt(v)
 	}
 
-	if err := v.ReadInConfig(); err != nil {
-		return config, err
+	// Without an explicit config file, ./config.yaml is optional and the
+	// config may come from the environment and flags alone.
+	if v.ConfigFileUsed() == "" {
+		if _, err := os.Stat(defaultFilePath); err == nil {
+			v.SetConfigFile(defaultFilePath)
+		}
+	}
+
+	var problems Problems
+	doc := &document{}
+	if file := v.ConfigFileUsed(); file != "" {
+		if err := v.ReadInConfig(); err != nil {
+			return config, nil, err
+		}
+
+		var err error
+		if doc, err = loadDocument(file); err != nil {
+			return config, nil, err
+		}
+		problems = doc.unknownKeys()
 	}
-	v.AutomaticEnv()
 
-	err := v.Unmarshal(&config)
+	if err := v.Unmarshal(&config); err != nil {
+		return config, nil, err
+	}
+
+	problems = append(problems, config.Validate()...)
+	if len(problems) == 0 {
+		for _, validate := range validators {
+			problems = append(problems, validate(config)...)
+		}
+	}
+	doc.locate(problems)
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
 
-	return config, err
+	return config, problems, nil
 }
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the parsed config file, used to report problems with the line
+// they occur on.
+type document struct {
+	path string
+	root *yaml.Node
+}
+
+func loadDocument(path string) (*document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return &document{path: path}, nil
+	}
+	return &document{path: path, root: root.Content[0]}, nil
+}
+
+// unknownKeys reports the keys in the document that do not map to a field of
+// the config. Keys are matched case insensitively, like viper does.
+func (d *document) unknownKeys() Problems {
+	if d.root == nil {
+		return nil
+	}
+	return unknownKeys(d.root, reflect.TypeOf(Config{}), "")
+}
+
+func unknownKeys(node *yaml.Node, t reflect.Type, prefix string) Problems {
+	var problems Problems
+	switch {
+	case t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Duration(0)):
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			field, ok := fieldByTag(t, keyNode.Value)
+			if !ok {
+				problems = append(problems, Problem{
+					Key:     prefix + keyNode.Value,
+					Line:    keyNode.Line,
+					Message: "unknown key",
+				})
+				continue
+			}
+			problems = append(problems, unknownKeys(valueNode, field.Type, prefix+field.Tag.Get("mapstructure")+".")...)
+		}
+	case t.Kind() == reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unknownKeys(node.Content[i+1], t.Elem(), prefix+node.Content[i].Value+".")...)
+		}
+	case t.Kind() == reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d].", strings.TrimSuffix(prefix, "."), i))...)
+		}
+	}
+	return problems
+}
+
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.EqualFold(field.Tag.Get("mapstructure"), name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// line returns the line key is set on, e.g. for customMetrics[1].uri, or 0
+// when it is not set in the document.
+func (d *document) line(key string) int {
+	if d.root == nil {
+		return 0
+	}
+
+	node := d.root
+	line := 0
+	for _, part := range splitKey(key) {
+		if node == nil {
+			break
+		}
+
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if strings.EqualFold(node.Content[i].Value, part) {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(part); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+// splitKey splits a key into its map keys and sequence indexes, e.g.
+// customMetrics[1].uri into customMetrics, 1 and uri.
+func splitKey(key string) []string {
+	key = strings.NewReplacer("[", ".", "]", "").Replace(key)
+	return strings.Split(key, ".")
+}
+
+func (d *document) locate(problems Problems) {
+	for i := range problems {
+		problems[i].File = d.path
+		if problems[i].Line == 0 {
+			problems[i].Line = d.line(problems[i].Key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes the environment variables overriding config keys, e.g.
+// REDFISH_EXPORTER_HOST_PASSWORD for host.password.
+const EnvPrefix = "REDFISH_EXPORTER"
+
+type key struct {
+	name string
+	typ  reflect.Type
+}
+
+// keys lists every leaf key of the config, e.g. host.endpoint, derived from
+// the mapstructure tags.
+func keys() []key {
+	return structKeys(reflect.TypeOf(Config{}), "")
+}
+
+func structKeys(t reflect.Type, prefix string) []key {
+	var result []key
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name := prefix + tag
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			result = append(result, structKeys(field.Type, name+".")...)
+			continue
+		}
+		result = append(result, key{name: name, typ: field.Type})
+	}
+	return result
+}
+
+// bindEnv makes every key settable from the environment. Viper only looks up
+// the environment for keys it already knows about, so keys that are neither
+// in the file nor have a default would be missed by Unmarshal otherwise.
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for _, k := range keys() {
+		_ = v.BindEnv(k.name)
+	}
+}
+
+// AddFlags defines a flag for every key that can be expressed on the command
+// line, e.g. --host.endpoint. Maps and lists of objects, such as metrics and
+// customMetrics, can only be set in the config file.
+func AddFlags(fs *pflag.FlagSet) {
+	for _, k := range keys() {
+		usage := fmt.Sprintf("overrides %s", k.name)
+		switch {
+		case k.typ == reflect.TypeOf(time.Duration(0)):
+			fs.Duration(k.name, 0, usage)
+		case k.typ.Kind() == reflect.String:
+			fs.String(k.name, "", usage)
+		case k.typ.Kind() == reflect.Bool:
+			fs.Bool(k.name, false, usage)
+		case k.typ.Kind() == reflect.Int:
+			fs.Int(k.name, 0, usage)
+		case k.typ.Kind() == reflect.Float64:
+			fs.Float64(k.name, 0, usage)
+		case k.typ == reflect.TypeOf([]string(nil)):
+			fs.StringSlice(k.name, nil, usage)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
 
 type Option func(*viper.Viper)
 
@@ -9,3 +12,23 @@ func WithFilePath(path string) Option {
 		v.SetConfigFile(path)
 	}
 }
+
+// WithFlags lets the flags defined by AddFlags override the config file and
+// the environment. Only flags set on the command line take effect.
+func WithFlags(fs *pflag.FlagSet) Option {
+	return func(v *viper.Viper) {
+		for _, k := range keys() {
+			if flag := fs.Lookup(k.name); flag != nil {
+				_ = v.BindPFlag(k.name, flag)
+			}
+		}
+	}
+}
+
+// WithValue sets key, taking precedence over flags, the environment and the
+// config file.
+func WithValue(key string, value interface{}) Option {
+	return func(v *viper.Viper) {
+		v.Set(key, value)
+	}
+}
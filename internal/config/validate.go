@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	logLevels      = []string{"debug", "info", "warn", "error"}
+	tlsVersions    = []string{"1.0", "1.1", "1.2", "1.3"}
+	rejectPolicies = []string{"reject", "stale"}
+	stateEncodings = []string{"numeric", "stateset"}
+	namings        = []string{"legacy", "v2", "both"}
+	otlpProtocols  = []string{"grpc", "http"}
+	eventModes     = []string{"push", "sse"}
+)
+
+// Problem is a single issue found in the config, identified by its key and,
+// when the key is set in the config file, its line.
+type Problem struct {
+	Key     string
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.File != "" && p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Key, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// Problems is returned as the error of New when the config is invalid.
+type Problems []Problem
+
+func (p Problems) Error() string {
+	messages := make([]string, 0, len(p))
+	for _, problem := range p {
+		messages = append(messages, problem.String())
+	}
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// Validate checks the values of the config for problems that would
+// otherwise only surface once the BMC is scraped.
+func (c Config) Validate() Problems {
+	var problems Problems
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !contains(logLevels, c.LogLevel) {
+		add("logLevel", "must be one of %s", strings.Join(logLevels, ", "))
+	}
+	if c.Web.Port < 1 || c.Web.Port > 65535 {
+		add("web.port", "must be between 1 and 65535")
+	}
+	if c.Web.ConfigFile != "" {
+		checkReadable(add, "web.configFile", c.Web.ConfigFile)
+	}
+
+	if c.Host.Endpoint == "" {
+		add("host.endpoint", "is required")
+	}
+	if c.Host.UsernameFile != "" {
+		checkReadable(add, "host.usernameFile", c.Host.UsernameFile)
+	}
+	if c.Host.PasswordFile != "" {
+		checkReadable(add, "host.passwordFile", c.Host.PasswordFile)
+	}
+	if c.Host.MaxConcurrentRequests < 0 {
+		add("host.maxConcurrentRequests", "must not be negative")
+	}
+	if c.Host.RequestsPerSecond < 0 {
+		add("host.requestsPerSecond", "must not be negative")
+	}
+
+	tls := c.Host.TLS
+	for key, file := range map[string]string{
+		"host.tls.caFile":   tls.CAFile,
+		"host.tls.certFile": tls.CertFile,
+		"host.tls.keyFile":  tls.KeyFile,
+	} {
+		if file != "" {
+			checkReadable(add, key, file)
+		}
+	}
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		add("host.tls", "certFile and keyFile must be set together")
+	}
+	if tls.MinVersion != "" && !contains(tlsVersions, tls.MinVersion) {
+		add("host.tls.minVersion", "must be one of %s", strings.Join(tlsVersions, ", "))
+	}
+	if tls.MaxVersion != "" && !contains(tlsVersions, tls.MaxVersion) {
+		add("host.tls.maxVersion", "must be one of %s", strings.Join(tlsVersions, ", "))
+	}
+
+	if !contains(eventModes, c.Events.Mode) {
+		add("events.mode", "must be one of %s", strings.Join(eventModes, ", "))
+	}
+	if c.Events.Enabled && c.Events.Mode == "push" {
+		if u, err := url.Parse(c.Events.Destination); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("events.destination", "must be an http or https URL when events are pushed")
+		}
+	}
+
+	if c.Polling.Enabled && c.Polling.Interval <= 0 {
+		add("polling.interval", "must be positive when polling is enabled")
+	}
+	if c.Polling.StaleAfter < 0 {
+		add("polling.staleAfter", "must not be negative")
+	}
+
+	if c.Scrape.Timeout < 0 {
+		add("scrape.timeout", "must not be negative")
+	}
+	if c.Scrape.TimeoutOffset < 0 {
+		add("scrape.timeoutOffset", "must not be negative")
+	}
+	if c.Scrape.MaxConcurrent < 0 {
+		add("scrape.maxConcurrent", "must not be negative")
+	}
+	if c.Scrape.MaxQueue < 0 {
+		add("scrape.maxQueue", "must not be negative")
+	}
+	if !contains(rejectPolicies, c.Scrape.RejectPolicy) {
+		add("scrape.rejectPolicy", "must be one of %s", strings.Join(rejectPolicies, ", "))
+	}
+
+	if !contains(otlpProtocols, c.Tracing.Protocol) {
+		add("tracing.protocol", "must be one of %s", strings.Join(otlpProtocols, ", "))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sampleRatio", "must be between 0 and 1")
+	}
+
+	if !contains(stateEncodings, c.Metrics.StateEncoding) {
+		add("metrics.stateEncoding", "must be one of %s", strings.Join(stateEncodings, ", "))
+	}
+	if !contains(namings, c.Metrics.Naming) {
+		add("metrics.naming", "must be one of %s", strings.Join(namings, ", "))
+	}
+	for name := range c.Metrics.Metrics {
+		if !model.IsValidLegacyMetricName(name) {
+			add("metrics.metrics."+name, "must be a valid metric name")
+		}
+	}
+
+	for i, metric := range c.CustomMetrics {
+		key := fmt.Sprintf("customMetrics[%d]", i)
+		if metric.Name == "" {
+			add(key+".name", "is required")
+		}
+		if metric.URI == "" {
+			add(key+".uri", "is required")
+		}
+	}
+
+	return problems
+}
+
+func checkReadable(add func(key, format string, args ...interface{}), key, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		add(key, "%s", err)
+		return
+	}
+	f.Close()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
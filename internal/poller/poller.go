@@ -0,0 +1,186 @@
+package poller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	scrapeStatusFamily = prometheus.BuildFQName(collectors.Namespace, "", "collector_scrape_status")
+)
+
+// Poller gathers the redfish collectors in the background and serves the
+// last complete snapshot, so slow BMCs do not hold up a Prometheus scrape.
+type Poller struct {
+	logger     *log.Logger
+	registry   *collectors.Registry
+	tracer     trace.Tracer
+	interval   time.Duration
+	staleAfter time.Duration
+
+	own           *prometheus.Registry
+	mu            sync.RWMutex
+	snapshot      []*dto.MetricFamily
+	status        *dto.MetricFamily
+	lastSuccess   time.Time
+	lastSuccessTS prometheus.Gauge
+	stale         prometheus.Gauge
+	duration      prometheus.Gauge
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+func New(cfg config.Config, logger *log.Logger, registry *collectors.Registry, tracer trace.Tracer) *Poller {
+	staleAfter := cfg.Polling.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = 3 * cfg.Polling.Interval
+	}
+
+	poller := &Poller{
+		logger:     logger,
+		own:        prometheus.NewRegistry(),
+		registry:   registry,
+		tracer:     tracer,
+		interval:   cfg.Polling.Interval,
+		staleAfter: staleAfter,
+		lastSuccessTS: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: collectors.Namespace,
+			Name:      "last_successful_poll_timestamp_seconds",
+			Help:      "Unix timestamp of the last background poll in which every collector succeeded",
+		}),
+		stale: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: collectors.Namespace,
+			Name:      "poll_stale",
+			Help:      "1 if the last successful poll is older than the staleness threshold and its metrics are no longer served",
+		}),
+		duration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: collectors.Namespace,
+			Name:      "poll_duration_seconds",
+			Help:      "Duration of the last background poll",
+		}),
+		done: make(chan struct{}),
+	}
+	poller.own.MustRegister(poller.lastSuccessTS, poller.stale, poller.duration)
+
+	return poller
+}
+
+func Start(poller *Poller, cfg config.Config, lc fx.Lifecycle) {
+	if !cfg.Polling.Enabled {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			poller.cancel = cancel
+			go poller.run(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			poller.cancel()
+			select {
+			case <-poller.done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+func (p *Poller) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) poll(ctx context.Context) {
+	p.logger.Debug("Polling redfish collectors")
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+	ctx, span := p.tracer.Start(ctx, "poll")
+	defer span.End()
+
+	start := time.Now()
+	families, err := p.registry.GatherContext(ctx)
+	p.duration.Set(time.Since(start).Seconds())
+	if err != nil {
+		p.logger.Error("Failed to gather redfish collectors", zap.Error(err))
+		tracing.Fail(span, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.status = nil
+	complete := err == nil && ctx.Err() == nil
+	for _, family := range families {
+		if family.GetName() != scrapeStatusFamily {
+			continue
+		}
+		p.status = family
+		for _, metric := range family.GetMetric() {
+			if metric.GetGauge().GetValue() != 1 {
+				complete = false
+			}
+		}
+	}
+
+	if !complete {
+		p.logger.Warn("Background poll was incomplete, keeping previous snapshot")
+		return
+	}
+
+	p.snapshot = families
+	p.lastSuccess = start
+	p.lastSuccessTS.Set(float64(start.Unix()))
+}
+
+// Gather implements prometheus.Gatherer. It returns the last complete
+// snapshot as long as it is not stale, together with the poller's own
+// metrics. A stale snapshot is replaced by the collector status of the latest
+// poll so failing collectors remain visible.
+func (p *Poller) Gather() ([]*dto.MetricFamily, error) {
+	return prometheus.Gatherers{
+		prometheus.GathererFunc(p.gatherSnapshot),
+		p.own,
+	}.Gather()
+}
+
+func (p *Poller) gatherSnapshot() ([]*dto.MetricFamily, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.snapshot != nil && time.Since(p.lastSuccess) <= p.staleAfter {
+		p.stale.Set(0)
+		return p.snapshot, nil
+	}
+
+	p.stale.Set(1)
+	if p.status != nil {
+		return []*dto.MetricFamily{p.status}, nil
+	}
+	return nil, nil
+}
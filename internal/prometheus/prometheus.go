@@ -3,27 +3,199 @@ package prometheus
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
+	redfishcollectors "github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/poller"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
-func NewRegistry() *prometheus.Registry {
-	return prometheus.NewRegistry()
+const (
+	StateEncodingNumeric  = "numeric"
+	StateEncodingStateSet = "stateset"
+
+	scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+)
+
+// RuntimeRegistry holds the exporter's own process and Go runtime metrics.
+// It is kept apart from the redfish registry so those metrics are always
+// gathered live, even when redfish metrics are served from a poll.
+type RuntimeRegistry struct {
+	*prometheus.Registry
 }
 
-func RegisterHandler(mux *http.ServeMux, reg *prometheus.Registry, lc fx.Lifecycle) {
+func NewRuntimeRegistry() *RuntimeRegistry {
+	return &RuntimeRegistry{prometheus.NewRegistry()}
+}
+
+// EncodingGatherer applies the naming scheme and state encoding configured
+// in cfg to the redfish metrics gathered by g.
+func EncodingGatherer(g prometheus.Gatherer, cfg config.Metrics) prometheus.Gatherer {
+	// Enum families are known by their legacy name, so they are encoded
+	// before being renamed.
+	if cfg.StateEncoding == StateEncodingStateSet {
+		g = redfishcollectors.StateSetGatherer(g)
+	}
+	return redfishcollectors.NamingGatherer(g, cfg.Naming)
+}
+
+func RegisterHandler(mux *http.ServeMux, reg *redfishcollectors.Registry, runtime *RuntimeRegistry, poller *poller.Poller, cfg config.Config, logger *log.Logger, tracer trace.Tracer, lc fx.Lifecycle) {
+	queue := newScrapeQueue(cfg.Scrape.MaxConcurrent, cfg.Scrape.MaxQueue)
+	handler := &scrapeHandler{
+		logger:  logger,
+		cfg:     cfg,
+		reg:     reg,
+		runtime: runtime,
+		poller:  poller,
+		queue:   queue,
+		tracer:  tracer,
+	}
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+			if err := registerAll(runtime, queue.collectors()); err != nil {
+				return err
+			}
+			mux.Handle("/metrics", handler)
 			return nil
 		},
 	})
 }
 
-func RegisterBasicCollectors(reg *prometheus.Registry, lc fx.Lifecycle) {
+type scrapeHandler struct {
+	logger  *log.Logger
+	cfg     config.Config
+	reg     *redfishcollectors.Registry
+	runtime *RuntimeRegistry
+	poller  *poller.Poller
+	queue   *scrapeQueue
+	tracer  trace.Tracer
+
+	mu   sync.RWMutex
+	last []*dto.MetricFamily
+}
+
+func (h *scrapeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.cfg.Polling.Enabled {
+		h.serve(w, r, h.poller)
+		return
+	}
+
+	ctx, cancel := scrapeContext(r, h.cfg.Scrape)
+	defer cancel()
+	ctx, span := h.tracer.Start(ctx, "scrape")
+	defer span.End()
+
+	release, err := h.queue.acquire(ctx)
+	if err != nil {
+		tracing.Fail(span, err)
+		h.reject(w, r, err)
+		return
+	}
+	defer release()
+
+	h.serve(w, r, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := h.reg.GatherContext(ctx)
+		if err == nil {
+			h.mu.Lock()
+			h.last = families
+			h.mu.Unlock()
+		}
+		return families, err
+	}))
+}
+
+// reject answers a scrape that could not get a worker, either with 429 or,
+// with the stale policy, with the result of the last completed scrape.
+func (h *scrapeHandler) reject(w http.ResponseWriter, r *http.Request, err error) {
+	h.logger.Warn("Rejecting scrape", zap.Error(err))
+
+	h.mu.RLock()
+	last := h.last
+	h.mu.RUnlock()
+
+	if h.cfg.Scrape.RejectPolicy == RejectPolicyStale && last != nil {
+		h.serve(w, r, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return last, nil
+		}))
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusTooManyRequests)
+}
+
+func (h *scrapeHandler) serve(w http.ResponseWriter, r *http.Request, redfish prometheus.Gatherer) {
+	// Gathering the redfish metrics first lets the request metrics in the
+	// runtime registry include the requests of this scrape.
+	gatherers := prometheus.Gatherers{EncodingGatherer(redfish, h.cfg.Metrics), h.runtime}
+	promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}).ServeHTTP(w, r)
+}
+
+// RegisterRequestMetrics exposes the metrics of the requests sent to the BMC
+// with the exporter's own metrics.
+func RegisterRequestMetrics(reg *RuntimeRegistry, metrics *redfish.RequestMetrics, lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return registerAll(reg, metrics.Collectors())
+		},
+	})
+}
+
+// RegisterEventMetrics exposes the event counters. They are kept with the
+// exporter's own metrics so they are current even when the redfish metrics
+// are served from a poll.
+func RegisterEventMetrics(reg *RuntimeRegistry, metrics *redfish.EventMetrics, lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return registerAll(reg, metrics.Collectors())
+		},
+	})
+}
+
+func registerAll(reg *RuntimeRegistry, collectors []prometheus.Collector) error {
+	for _, collector := range collectors {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scrapeContext derives the deadline of a scrape from the timeout Prometheus
+// sends in X-Prometheus-Scrape-Timeout-Seconds, falling back to the
+// configured timeout. The offset leaves time to encode and send the response.
+func scrapeContext(r *http.Request, cfg config.Scrape) (context.Context, context.CancelFunc) {
+	timeout := cfg.Timeout
+	if header := r.Header.Get(scrapeTimeoutHeader); header != "" {
+		if seconds, err := strconv.ParseFloat(header, 64); err == nil {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	if timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	if timeout > cfg.TimeoutOffset {
+		timeout -= cfg.TimeoutOffset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+func RegisterBasicCollectors(reg *RuntimeRegistry, lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			err := reg.Register(
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	redfishcollectors "github.com/FreekingDean/redfish_exporter/internal/collectors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	RejectPolicyReject = "reject"
+	RejectPolicyStale  = "stale"
+)
+
+var errQueueFull = errors.New("scrape queue is full")
+
+// scrapeQueue limits the number of scrapes walking the BMC at once. Scrapes
+// beyond the limit wait in FIFO order, up to maxQueue of them; further
+// scrapes are rejected.
+type scrapeQueue struct {
+	workers  chan struct{}
+	maxQueue int
+
+	mu      sync.Mutex
+	waiting int
+
+	depth    prometheus.Gauge
+	wait     prometheus.Histogram
+	rejected prometheus.Counter
+}
+
+func newScrapeQueue(maxConcurrent, maxQueue int) *scrapeQueue {
+	q := &scrapeQueue{
+		maxQueue: maxQueue,
+		depth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: redfishcollectors.ExporterNamespace,
+			Name:      "probe_queue_depth",
+			Help:      "Number of scrapes waiting for a free worker",
+		}),
+		wait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: redfishcollectors.ExporterNamespace,
+			Name:      "probe_wait_seconds",
+			Help:      "Time scrapes spent waiting for a free worker",
+			Buckets:   []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60},
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: redfishcollectors.ExporterNamespace,
+			Name:      "probe_rejected_total",
+			Help:      "Number of scrapes rejected because the queue was full",
+		}),
+	}
+	if maxConcurrent > 0 {
+		q.workers = make(chan struct{}, maxConcurrent)
+	}
+	return q
+}
+
+func (q *scrapeQueue) collectors() []prometheus.Collector {
+	return []prometheus.Collector{q.depth, q.wait, q.rejected}
+}
+
+// acquire blocks until a worker is free and returns the function releasing
+// it. It fails when the queue is full or ctx is done while waiting.
+func (q *scrapeQueue) acquire(ctx context.Context) (func(), error) {
+	if q.workers == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case q.workers <- struct{}{}:
+		q.wait.Observe(0)
+		return q.release, nil
+	default:
+	}
+
+	q.mu.Lock()
+	if q.waiting >= q.maxQueue {
+		q.mu.Unlock()
+		q.rejected.Inc()
+		return nil, errQueueFull
+	}
+	q.waiting++
+	q.depth.Set(float64(q.waiting))
+	q.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		q.mu.Lock()
+		q.waiting--
+		q.depth.Set(float64(q.waiting))
+		q.mu.Unlock()
+		q.wait.Observe(time.Since(start).Seconds())
+	}()
+
+	select {
+	case q.workers <- struct{}{}:
+		return q.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (q *scrapeQueue) release() {
+	<-q.workers
+}
@@ -0,0 +1,76 @@
+package redfish
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// clientCertificate loads the client certificate for mTLS and reloads it
+// whenever the certificate or key file changes, so rotated certificates are
+// picked up on the next handshake without a restart.
+type clientCertificate struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func newClientCertificate(certFile, keyFile string) (*clientCertificate, error) {
+	c := &clientCertificate{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := c.GetClientCertificate(nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *clientCertificate) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modified, err := c.lastModified()
+	if err != nil {
+		if c.cert != nil {
+			// Rotation may replace the files non-atomically, keep using the
+			// previous certificate until both are back.
+			return c.cert, nil
+		}
+		return nil, err
+	}
+
+	if c.cert != nil && modified.Equal(c.modified) {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			return c.cert, nil
+		}
+		return nil, err
+	}
+
+	c.cert = &cert
+	c.modified = modified
+	return c.cert, nil
+}
+
+func (c *clientCertificate) lastModified() (time.Time, error) {
+	var modified time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	return modified, nil
+}
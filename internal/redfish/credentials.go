@@ -0,0 +1,164 @@
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/transport"
+	"github.com/stmcginnis/gofish"
+)
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// credentials resolves the username and password of host. Values may
+// reference environment variables as ${NAME}, and are read from usernameFile
+// and passwordFile when those are set, so rotated secrets are picked up the
+// next time they are resolved.
+func credentials(host config.Host) (string, string, error) {
+	username, err := credential(host.Username, host.UsernameFile)
+	if err != nil {
+		return "", "", fmt.Errorf("username: %w", err)
+	}
+
+	password, err := credential(host.Password, host.PasswordFile)
+	if err != nil {
+		return "", "", fmt.Errorf("password: %w", err)
+	}
+
+	return username, password, nil
+}
+
+func credential(value, file string) (string, error) {
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	var err error
+	value = envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envReference.FindStringSubmatch(ref)[1]
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return env
+	})
+	return value, err
+}
+
+// authenticator logs in to the BMC and shares the session between all
+// clients. A new session, with freshly resolved credentials, is created once
+// the BMC answers a request with 401.
+type authenticator struct {
+	host config.Host
+
+	mu      sync.Mutex
+	session *gofish.Session
+	// client is the client of the current session, whose service root is
+	// shared by all clients until the session expires.
+	client  *gofish.APIClient
+	expired atomic.Bool
+}
+
+func newAuthenticator(host config.Host) *authenticator {
+	return &authenticator{host: host}
+}
+
+// connect returns the client of the current session, logging in with ctx if
+// there is none or it expired. The client is not bound to ctx, see bind.
+func (a *authenticator) connect(ctx context.Context, config gofish.ClientConfig) (*gofish.APIClient, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.client != nil && !a.expired.Load() {
+		return a.client, nil
+	}
+
+	// The client certificate authenticates every request, without it gofish
+	// would log in and create a session.
+	if a.host.TLS.CertFile == "" {
+		if err := a.resolve(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	// The requests of the login are sent with ctx, but the client keeps a
+	// context that is never cancelled, as it outlives ctx.
+	httpClient := config.HTTPClient
+	config.HTTPClient = withContext(httpClient, ctx)
+	client, err := gofish.ConnectContext(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	client.HTTPClient = httpClient
+
+	if session, err := client.GetSession(); err == nil {
+		a.session = session
+	}
+	a.client = client
+	a.expired.Store(false)
+	return client, nil
+}
+
+func (a *authenticator) resolve(config *gofish.ClientConfig) error {
+	username, password, err := credentials(a.host)
+	if err != nil {
+		return err
+	}
+	config.Username = username
+	config.Password = password
+	return nil
+}
+
+func (a *authenticator) currentSession() *gofish.Session {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session
+}
+
+// transport marks the session as expired when the BMC rejects a request.
+func (a *authenticator) transport(next http.RoundTripper) http.RoundTripper {
+	return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && req.Header.Get("X-Auth-Token") != "" {
+			a.expired.Store(true)
+		}
+		return resp, err
+	})
+}
+
+// bind returns a copy of client, and of its service root, whose requests are
+// sent with ctx. Unlike connecting again it sends no requests.
+func bind(client *gofish.APIClient, ctx context.Context) *gofish.APIClient {
+	bound := *client
+	bound.HTTPClient = withContext(client.HTTPClient, ctx)
+	if client.Service != nil {
+		service := *client.Service
+		service.SetClient(&bound)
+		bound.Service = &service
+	}
+	return &bound
+}
+
+// withContext returns a copy of httpClient that sends its requests with ctx
+// instead of their own context.
+func withContext(httpClient *http.Client, ctx context.Context) *http.Client {
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	bound := *httpClient
+	bound.Transport = transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return next.RoundTrip(req.WithContext(ctx))
+	})
+	return &bound
+}
@@ -0,0 +1,37 @@
+package redfish
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// endpointURL turns the configured endpoint into the base URL requests are
+// sent to. Bare hosts, optionally with a port, default to https. Full URLs
+// may use http and carry a path prefix, e.g. for a reverse proxy in front of
+// the BMC; the Redfish paths are appended to it.
+func endpointURL(endpoint string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid endpoint %q: unsupported scheme %q", endpoint, u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid endpoint %q: missing host", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid endpoint %q: query and fragment are not supported", endpoint)
+	}
+
+	// Accept the service root itself, which is what people tend to copy.
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/redfish/v1")
+	u.RawPath = ""
+
+	return u.String(), nil
+}
@@ -0,0 +1,315 @@
+package redfish
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stmcginnis/gofish/redfish"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	EventModePush = "push"
+	EventModeSSE  = "sse"
+
+	minSubscriptionBackoff = time.Second
+	maxSubscriptionBackoff = 5 * time.Minute
+)
+
+// EventSubscription is the subscription of the exporter to the EventService
+// of the BMC. Its context is a random secret the BMC sends with every event,
+// which verifies that the event comes from the BMC.
+type EventSubscription struct {
+	destination string
+	context     string
+
+	mu  sync.Mutex
+	uri string
+}
+
+func NewEventSubscription(cfg config.Config) (*EventSubscription, error) {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &EventSubscription{
+		destination: cfg.Events.Destination,
+		context:     hex.EncodeToString(secret),
+	}, nil
+}
+
+// Event is an event pushed by the BMC.
+type Event struct {
+	redfish.Event
+	// recordContexts are the contexts of the records, which BMCs
+	// implementing Event v1.0 send instead of the context of the event.
+	recordContexts []string
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var t struct {
+		Events []struct {
+			Context string
+		}
+	}
+	if err := json.Unmarshal(b, &e.Event); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+
+	e.recordContexts = nil
+	for _, record := range t.Events {
+		e.recordContexts = append(e.recordContexts, record.Context)
+	}
+	return nil
+}
+
+// Verify reports whether event was sent for this subscription. Without a
+// context of the event, every record has to carry the context.
+func (s *EventSubscription) Verify(event *Event) bool {
+	if event.Context != "" {
+		return s.verify(event.Context)
+	}
+	if len(event.recordContexts) == 0 {
+		return false
+	}
+	for _, context := range event.recordContexts {
+		if !s.verify(context) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *EventSubscription) verify(context string) bool {
+	return subtle.ConstantTimeCompare([]byte(context), []byte(s.context)) == 1
+}
+
+// Subscribe subscribes to the events of the BMC when they are pushed. It
+// has to be invoked after the server is started, as BMCs may send a test
+// event to the destination right away. Failing to subscribe does not stop
+// the exporter from starting, the subscription is retried in the background
+// with exponential backoff. On stop the subscription is deleted before the
+// server is stopped.
+func Subscribe(client *Client, subscription *EventSubscription, metrics *EventMetrics, cfg config.Config, logger *log.Logger, lc fx.Lifecycle) {
+	if !cfg.Events.Enabled || cfg.Events.Mode != EventModePush {
+		return
+	}
+
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				subscription.run(ctx, client, metrics, logger)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return nil
+			}
+			if err := subscription.unsubscribe(ctx, client); err != nil {
+				logger.Warn("Failed to delete event subscription", zap.Error(err))
+			}
+			return nil
+		},
+	})
+}
+
+// run subscribes until it succeeds or ctx is done.
+func (s *EventSubscription) run(ctx context.Context, client *Client, metrics *EventMetrics, logger *log.Logger) {
+	backoff := minSubscriptionBackoff
+	for {
+		err := s.subscribe(ctx, client)
+		if err == nil {
+			logger.Info("Subscribed to events", zap.String("destination", s.destination))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.subscriptionFailures.WithLabelValues(metrics.target).Inc()
+		logger.Error("Failed to subscribe to events, retrying", zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxSubscriptionBackoff)
+	}
+}
+
+// subscribe creates the subscription. Subscriptions to the same destination
+// are left behind by earlier runs that did not shut down cleanly. Their
+// context is unknown, so they are replaced.
+func (s *EventSubscription) subscribe(ctx context.Context, client *Client) error {
+	client, err := client.WithContext(ctx)
+	if err != nil {
+		return err
+	}
+	service, err := client.GetService().EventService()
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := service.GetEventSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		if subscription.Destination != s.destination {
+			continue
+		}
+		if err := service.DeleteEventSubscription(subscription.ODataID); err != nil {
+			return err
+		}
+	}
+
+	uri, err := service.CreateEventSubscriptionInstance(
+		s.destination,
+		nil,
+		nil,
+		nil,
+		redfish.RedfishEventDestinationProtocol,
+		s.context,
+		"",
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	if uri == "" {
+		return errors.New("the BMC did not return the location of the subscription")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uri = uri
+	return nil
+}
+
+// unsubscribe deletes the subscription, if it was created.
+func (s *EventSubscription) unsubscribe(ctx context.Context, client *Client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uri == "" {
+		return nil
+	}
+
+	client, err := client.WithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := redfish.DeleteEventDestination(client, s.uri); err != nil {
+		return err
+	}
+	s.uri = ""
+	return nil
+}
+
+// EventMetrics counts the events sent by the BMC by severity and message.
+type EventMetrics struct {
+	target               string
+	events               *prometheus.CounterVec
+	last                 *prometheus.GaugeVec
+	subscriptionFailures *prometheus.CounterVec
+}
+
+func NewEventMetrics(cfg config.Config) (*EventMetrics, error) {
+	endpoint, err := endpointURL(cfg.Host.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventMetrics{
+		target: u.Host,
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "events_total",
+			Help:      "Number of events sent by the BMC by severity and message, without the version of the message registry",
+		}, []string{"target", "severity", "message_id"}),
+		last: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "last_event_timestamp_seconds",
+			Help:      "Unix timestamp of the last event received from the BMC",
+		}, []string{"target"}),
+		subscriptionFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ExporterNamespace,
+			Name:      "event_subscription_failures_total",
+			Help:      "Number of failed attempts to subscribe to the events of the BMC",
+		}, []string{"target"}),
+	}, nil
+}
+
+func (m *EventMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.events, m.last, m.subscriptionFailures}
+}
+
+// Record counts the records of event.
+func (m *EventMetrics) Record(event *Event) {
+	for _, record := range event.Events {
+		m.events.WithLabelValues(m.target, severity(record), messageID(record.MessageID)).Inc()
+	}
+	m.last.WithLabelValues(m.target).Set(float64(time.Now().Unix()))
+}
+
+// LogEvent forwards the records of event to the log.
+func LogEvent(logger *log.Logger, event *Event) {
+	for _, record := range event.Events {
+		logger.Info(record.Message,
+			zap.String("event", "redfish"),
+			zap.String("severity", severity(record)),
+			zap.String("message_id", record.MessageID),
+			zap.String("origin", record.OriginOfCondition),
+			zap.String("timestamp", record.EventTimestamp),
+		)
+	}
+}
+
+// severity returns the severity of record, which older BMCs report in the
+// deprecated Severity property.
+func severity(record EventRecord) string {
+	if record.MessageSeverity != "" {
+		return string(record.MessageSeverity)
+	}
+	return record.Severity
+}
+
+// messageID strips the version from a message ID, e.g.
+// ResourceEvent.1.0.ResourceErrorsDetected, so firmware updates do not
+// start new series.
+func messageID(id string) string {
+	parts := strings.Split(id, ".")
+	if len(parts) < 4 {
+		return id
+	}
+	return parts[0] + "." + parts[len(parts)-1]
+}
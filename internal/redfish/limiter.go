@@ -0,0 +1,105 @@
+package redfish
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limitedTransport bounds the number of requests in flight to a BMC and
+// optionally spaces them out to a maximum rate. A request holds its slot
+// until the response body is closed, since BMCs are busy until the body has
+// been sent.
+type limitedTransport struct {
+	transport http.RoundTripper
+	slots     chan struct{}
+	interval  time.Duration
+
+	mu       sync.Mutex
+	nextSlot time.Time
+}
+
+func newLimitedTransport(transport http.RoundTripper, maxConcurrent int, requestsPerSecond float64) *limitedTransport {
+	t := &limitedTransport{
+		transport: transport,
+		slots:     make(chan struct{}, maxConcurrent),
+	}
+	if requestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return t
+}
+
+type unlimitedKey struct{}
+
+// withoutLimit exempts the requests made with ctx from the limits, for
+// streams that would otherwise hold a slot for as long as they are open.
+func withoutLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unlimitedKey{}, true)
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(unlimitedKey{}) != nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := sync.OnceFunc(func() { <-t.slots })
+
+	if delay := t.reserve(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// reserve returns how long to wait before the next request may be sent to
+// honour the configured rate.
+func (t *limitedTransport) reserve() time.Duration {
+	if t.interval <= 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	slot := t.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	t.nextSlot = slot.Add(t.interval)
+	return slot.Sub(now)
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,136 @@
+package redfish
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/transport"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The namespaces are exported as collectors.Namespace and
+// collectors.ExporterNamespace, they are declared here as the collectors
+// import this package.
+const (
+	Namespace         = "redfish"
+	ExporterNamespace = Namespace + "_exporter"
+)
+
+// collections are the Redfish collections whose member IDs are replaced in
+// path templates. Segments containing digits are treated as IDs as well, to
+// cover vendor specific collections.
+var collections = map[string]bool{
+	"Accounts":               true,
+	"Batteries":              true,
+	"Branches":               true,
+	"Certificates":           true,
+	"Chassis":                true,
+	"Controllers":            true,
+	"Drives":                 true,
+	"Endpoints":              true,
+	"Entries":                true,
+	"EthernetInterfaces":     true,
+	"FabricAdapters":         true,
+	"Fabrics":                true,
+	"Feeders":                true,
+	"FirmwareInventory":      true,
+	"FloorPDUs":              true,
+	"HostInterfaces":         true,
+	"LogServices":            true,
+	"Mains":                  true,
+	"Managers":               true,
+	"Memory":                 true,
+	"NetworkAdapters":        true,
+	"NetworkDeviceFunctions": true,
+	"NetworkInterfaces":      true,
+	"NetworkPorts":           true,
+	"Outlets":                true,
+	"PCIeDevices":            true,
+	"PCIeFunctions":          true,
+	"Ports":                  true,
+	"PowerShelves":           true,
+	"PowerSupplies":          true,
+	"Processors":             true,
+	"RackPDUs":               true,
+	"Roles":                  true,
+	"Sensors":                true,
+	"SerialInterfaces":       true,
+	"Sessions":               true,
+	"SimpleStorage":          true,
+	"SoftwareInventory":      true,
+	"Storage":                true,
+	"Subfeeds":               true,
+	"Subscriptions":          true,
+	"Switches":               true,
+	"Systems":                true,
+	"Tasks":                  true,
+	"TransferSwitches":       true,
+	"VirtualMedia":           true,
+	"Volumes":                true,
+	"Zones":                  true,
+}
+
+// RequestMetrics counts the requests sent to the BMC and their latency per
+// path template, e.g. /redfish/v1/Chassis/{id}/Thermal.
+type RequestMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ExporterNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of requests sent to the BMC by status code, error if no response was received",
+		}, []string{"target", "path_template", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ExporterNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time until the BMC sent the response headers",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"target", "path_template"}),
+	}
+}
+
+func (m *RequestMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.duration}
+}
+
+func (m *RequestMetrics) transport(next http.RoundTripper) http.RoundTripper {
+	return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+
+		path := pathTemplate(req.URL.Path)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		m.requests.WithLabelValues(req.URL.Host, path, code).Inc()
+		m.duration.WithLabelValues(req.URL.Host, path).Observe(time.Since(start).Seconds())
+		return resp, err
+	})
+}
+
+// pathTemplate replaces the IDs in a Redfish URI with {id} to bound the
+// number of series. The template starts at /redfish/v1, so the path prefix
+// of an endpoint behind a reverse proxy is dropped.
+func pathTemplate(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	start := 0
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "redfish" && segments[i+1] == "v1" {
+			segments, start = segments[i:], 2
+			break
+		}
+	}
+	for i := start; i < len(segments); i++ {
+		if (i > 0 && collections[segments[i-1]]) || strings.ContainsAny(segments[i], "0123456789") {
+			segments[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
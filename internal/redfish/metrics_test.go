@@ -0,0 +1,26 @@
+package redfish
+
+import "testing"
+
+func TestPathTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "/redfish/v1/", expected: "/redfish/v1"},
+		{path: "/redfish/v1/Chassis", expected: "/redfish/v1/Chassis"},
+		{path: "/redfish/v1/Chassis/1U/Thermal", expected: "/redfish/v1/Chassis/{id}/Thermal"},
+		{path: "/redfish/v1/Systems/System.Embedded.1/Storage/RAID.Slot.6-1", expected: "/redfish/v1/Systems/{id}/Storage/{id}"},
+		{path: "/redfish/v1/Chassis/Self/Oem/Vendor/Fan2", expected: "/redfish/v1/Chassis/{id}/Oem/Vendor/{id}"},
+		{path: "/bmc/redfish/v1/Managers/BMC", expected: "/redfish/v1/Managers/{id}"},
+		{path: "/proxy/rack12/node3/redfish/v1/Chassis/1U", expected: "/redfish/v1/Chassis/{id}"},
+		{path: "/bmc/12", expected: "/bmc/{id}"},
+		{path: "/", expected: "/"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := pathTemplate(tc.path); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
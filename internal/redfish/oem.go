@@ -0,0 +1,23 @@
+package redfish
+
+import (
+	"encoding/json"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/oem/smc"
+)
+
+// SupermicroNodeManager returns the node manager linked from the system's
+// Oem.Supermicro section, or nil if the system does not link one.
+func SupermicroNodeManager(system *ComputerSystem) (*smc.NodeManager, error) {
+	var oem struct {
+		Supermicro struct {
+			NodeManager common.Link
+		}
+	}
+	if err := json.Unmarshal(system.OEM, &oem); err != nil || oem.Supermicro.NodeManager == "" {
+		return nil, nil
+	}
+
+	return smc.GetNodeManager(system.GetClient(), oem.Supermicro.NodeManager.String())
+}
@@ -0,0 +1,288 @@
+package redfish
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// Readings holds the numeric properties of a Redfish object as the BMC sent
+// them, with nested properties joined by dots, e.g.
+// PowerMetrics.AverageConsumedWatts. gofish decodes null and missing numbers
+// as 0, which is a valid reading; here they are absent instead.
+type Readings map[string]float64
+
+func (r *Readings) UnmarshalJSON(b []byte) error {
+	var properties map[string]interface{}
+	if err := json.Unmarshal(b, &properties); err != nil {
+		return err
+	}
+
+	*r = make(Readings)
+	r.add("", properties)
+	return nil
+}
+
+func (r Readings) add(prefix string, properties map[string]interface{}) {
+	for name, value := range properties {
+		switch value := value.(type) {
+		case float64:
+			r[prefix+name] = value
+		case map[string]interface{}:
+			r.add(prefix+name+".", value)
+		}
+	}
+}
+
+// Get returns the value of property and whether the BMC reported one.
+func (r Readings) Get(property string) (float64, bool) {
+	value, ok := r[property]
+	return value, ok
+}
+
+// Thermal is the thermal resource of a chassis, with the readings of its
+// sensors and fans.
+type Thermal struct {
+	*redfish.Thermal
+	Temperatures []Temperature
+	Fans         []Fan
+}
+
+type Temperature struct {
+	redfish.Temperature
+	Readings Readings
+}
+
+type Fan struct {
+	redfish.ThermalFan
+	Readings Readings
+}
+
+// Power is the power resource of a chassis, with the readings of its
+// voltage sensors, power supplies and power controls.
+type Power struct {
+	*redfish.Power
+	Voltages      []Voltage
+	PowerSupplies []PowerSupply
+	PowerControl  []PowerControl
+}
+
+type Voltage struct {
+	redfish.Voltage
+	Readings Readings
+}
+
+type PowerSupply struct {
+	redfish.PowerSupply
+	Readings Readings
+}
+
+type PowerControl struct {
+	redfish.PowerControl
+	Readings Readings
+}
+
+// GetThermal returns the thermal resource linked from chassis, or nil if it
+// does not link one.
+func GetThermal(chassis *Chassis) (*Thermal, error) {
+	thermal := &Thermal{Thermal: &redfish.Thermal{}}
+	var readings struct {
+		Temperatures []Readings
+		Fans         []Readings
+	}
+
+	found, err := getLinked(chassis, "Thermal", thermal.Thermal, &readings)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	for i, temperature := range thermal.Thermal.Temperatures {
+		thermal.Temperatures = append(thermal.Temperatures, Temperature{temperature, readingsAt(readings.Temperatures, i)})
+	}
+	for i, fan := range thermal.Thermal.Fans {
+		thermal.Fans = append(thermal.Fans, Fan{fan, readingsAt(readings.Fans, i)})
+	}
+	return thermal, nil
+}
+
+// GetPower returns the power resource linked from chassis, or nil if it does
+// not link one.
+func GetPower(chassis *Chassis) (*Power, error) {
+	power := &Power{Power: &redfish.Power{}}
+	var readings struct {
+		Voltages      []Readings
+		PowerSupplies []Readings
+		PowerControl  []Readings
+	}
+
+	found, err := getLinked(chassis, "Power", power.Power, &readings)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	for i, voltage := range power.Power.Voltages {
+		power.Voltages = append(power.Voltages, Voltage{voltage, readingsAt(readings.Voltages, i)})
+	}
+	for i, powerSupply := range power.Power.PowerSupplies {
+		power.PowerSupplies = append(power.PowerSupplies, PowerSupply{powerSupply, readingsAt(readings.PowerSupplies, i)})
+	}
+	for i, powerControl := range power.Power.PowerControl {
+		power.PowerControl = append(power.PowerControl, PowerControl{powerControl, readingsAt(readings.PowerControl, i)})
+	}
+	return power, nil
+}
+
+// Outlet is an outlet of a power distribution unit with the readings of its
+// sensors.
+type Outlet struct {
+	*redfish.Outlet
+	Readings Readings
+}
+
+// Circuit is a mains or branch circuit of a power distribution unit with the
+// readings of its sensors.
+type Circuit struct {
+	*redfish.Circuit
+	Readings Readings
+}
+
+// NetworkAdapter is a network adapter of a chassis with the readings of its
+// metrics.
+type NetworkAdapter struct {
+	*redfish.NetworkAdapter
+	Readings Readings
+}
+
+// GetOutlets returns the outlets of pdu.
+func GetOutlets(pdu *PowerDistribution) ([]Outlet, error) {
+	outlets := []Outlet{}
+	err := getPDUMembers(pdu, "Outlets", func(body []byte, readings Readings) error {
+		outlet := &redfish.Outlet{}
+		if err := json.Unmarshal(body, outlet); err != nil {
+			return err
+		}
+		outlet.SetClient(pdu.GetClient())
+		outlets = append(outlets, Outlet{outlet, readings})
+		return nil
+	})
+	return outlets, err
+}
+
+// GetCircuits returns the circuits of pdu linked under property, i.e. Mains
+// or Branches.
+func GetCircuits(pdu *PowerDistribution, property string) ([]Circuit, error) {
+	circuits := []Circuit{}
+	err := getPDUMembers(pdu, property, func(body []byte, readings Readings) error {
+		circuit := &redfish.Circuit{}
+		if err := json.Unmarshal(body, circuit); err != nil {
+			return err
+		}
+		circuit.SetClient(pdu.GetClient())
+		circuits = append(circuits, Circuit{circuit, readings})
+		return nil
+	})
+	return circuits, err
+}
+
+// GetNetworkAdapters returns the network adapters of chassis.
+func GetNetworkAdapters(chassis *Chassis) ([]NetworkAdapter, error) {
+	adapters := []NetworkAdapter{}
+	err := getMembers(chassis.GetClient(), chassis.RawData, "NetworkAdapters", func(body []byte, readings Readings) error {
+		adapter := &redfish.NetworkAdapter{}
+		if err := json.Unmarshal(body, adapter); err != nil {
+			return err
+		}
+		adapter.SetClient(chassis.GetClient())
+		adapters = append(adapters, NetworkAdapter{adapter, readings})
+		return nil
+	})
+	return adapters, err
+}
+
+// getPDUMembers calls decode for every member of the collection linked from
+// pdu under property. gofish does not keep the body of a power distribution
+// unit, so it is fetched again for its links.
+func getPDUMembers(pdu *PowerDistribution, property string, decode func([]byte, Readings) error) error {
+	body, err := get(pdu.GetClient(), pdu.ODataID)
+	if err != nil {
+		return err
+	}
+	return getMembers(pdu.GetClient(), body, property, decode)
+}
+
+// getMembers calls decode with the body and the readings of every member of
+// the collection linked from the object in body under property.
+func getMembers(client common.Client, body []byte, property string, decode func([]byte, Readings) error) error {
+	var links map[string]json.RawMessage
+	if err := json.Unmarshal(body, &links); err != nil {
+		return err
+	}
+
+	var link common.Link
+	if raw, ok := links[property]; !ok || json.Unmarshal(raw, &link) != nil || link == "" {
+		return nil
+	}
+
+	collection, err := common.GetCollection(client, link.String())
+	if err != nil {
+		return err
+	}
+	for _, member := range collection.ItemLinks {
+		body, err := get(client, member)
+		if err != nil {
+			return err
+		}
+		var readings Readings
+		if err := json.Unmarshal(body, &readings); err != nil {
+			return err
+		}
+		if err := decode(body, readings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func get(client common.Client, uri string) ([]byte, error) {
+	resp, err := client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// getLinked fetches the object linked from chassis under property once and
+// decodes it both into object and into readings.
+func getLinked(chassis *Chassis, property string, object common.SchemaObject, readings interface{}) (bool, error) {
+	var links map[string]json.RawMessage
+	if err := json.Unmarshal(chassis.RawData, &links); err != nil {
+		return false, err
+	}
+
+	var link common.Link
+	if raw, ok := links[property]; !ok || json.Unmarshal(raw, &link) != nil || link == "" {
+		return false, nil
+	}
+
+	body, err := get(chassis.GetClient(), link.String())
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(body, object); err != nil {
+		return false, err
+	}
+	object.SetClient(chassis.GetClient())
+
+	return true, json.Unmarshal(body, readings)
+}
+
+func readingsAt(readings []Readings, i int) Readings {
+	if i < len(readings) {
+		return readings[i]
+	}
+	return Readings{}
+}
@@ -2,8 +2,6 @@ package redfish
 
 import (
 	"context"
-	"crypto/tls"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -12,6 +10,7 @@ import (
 	"github.com/stmcginnis/gofish"
 	"github.com/stmcginnis/gofish/common"
 	"github.com/stmcginnis/gofish/redfish"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -33,6 +32,16 @@ const (
 	StateQuiesced           = common.QuiescedState
 	StateUpdating           = common.UpdatingState
 
+	PowerStateOn          = redfish.OnPowerState
+	PowerStateOff         = redfish.OffPowerState
+	PowerStatePoweringOn  = redfish.PoweringOnPowerState
+	PowerStatePoweringOff = redfish.PoweringOffPowerState
+	PowerStatePaused      = redfish.PausedPowerState
+
+	BreakerStateNormal  = redfish.NormalBreakerState
+	BreakerStateTripped = redfish.TrippedBreakerState
+	BreakerStateOff     = redfish.OffBreakerState
+
 	PercentReadingUnits = redfish.PercentReadingUnits
 
 	NetworkPortLinkStatusUp   = redfish.UpPortLinkStatus
@@ -40,13 +49,27 @@ const (
 )
 
 type (
-	Chassis = redfish.Chassis
-	Thermal = redfish.Thermal
-	Health  = common.Health
-	State   = common.State
+	Service           = gofish.Service
+	Link              = common.Link
+	Chassis           = redfish.Chassis
+	ComputerSystem    = redfish.ComputerSystem
+	PowerEquipment    = redfish.PowerEquipment
+	PowerDistribution = redfish.PowerDistribution
+	Health            = common.Health
+	State             = common.State
+	PowerState        = redfish.PowerState
+	BreakerState      = redfish.BreakerState
+	EventRecord       = redfish.EventRecord
 )
 
-func NewClientConfig(cfg config.Config) *gofish.ClientConfig {
+// NewClientConfig builds the client config for the configured host. Requests
+// are counted in metrics and traced with tracer unless they are nil.
+func NewClientConfig(cfg config.Config, metrics *RequestMetrics, tracer trace.Tracer) (*gofish.ClientConfig, error) {
+	tlsConfig, err := newTLSConfig(cfg.Host.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	defaultTransport := http.DefaultTransport.(*http.Transport)
 	transport := &http.Transport{
 		Proxy:                 defaultTransport.Proxy,
@@ -55,73 +78,118 @@ func NewClientConfig(cfg config.Config) *gofish.ClientConfig {
 		IdleConnTimeout:       defaultTransport.IdleConnTimeout,
 		ExpectContinueTimeout: defaultTransport.ExpectContinueTimeout,
 		TLSHandshakeTimeout:   time.Duration(10) * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-			// Manually Added additional CipherSuites to support TLS 1.0
-			CipherSuites: []uint16{
-				// TLS 1.0 - 1.2 cipher suites.
-				tls.TLS_RSA_WITH_RC4_128_SHA,
-				tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
-				tls.TLS_RSA_WITH_AES_128_CBC_SHA,
-				tls.TLS_RSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
-				tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
-				tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
-				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
-				tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
-				tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
-				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
-				tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
-				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
-				// TLS 1.3 cipher suites.
-				tls.TLS_AES_128_GCM_SHA256,
-				tls.TLS_AES_256_GCM_SHA384,
-				tls.TLS_CHACHA20_POLY1305_SHA256,
-			},
-		},
+		TLSClientConfig:       tlsConfig,
+	}
+
+	// Instrumenting below the limiter leaves out the time spent waiting for
+	// a free slot, so the latency is that of the BMC.
+	var instrumented http.RoundTripper = transport
+	if metrics != nil {
+		instrumented = metrics.transport(instrumented)
+	}
+	if tracer != nil {
+		instrumented = tracingTransport(tracer, instrumented)
+	}
+
+	maxConcurrent := cfg.Host.MaxConcurrentRequests
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	endpoint, err := endpointURL(cfg.Host.Endpoint)
+	if err != nil {
+		return nil, err
 	}
 
 	config := gofish.ClientConfig{
-		Endpoint:   fmt.Sprintf("https://%s", cfg.Host.Endpoint),
-		Username:   cfg.Host.Username,
-		Password:   cfg.Host.Password,
-		BasicAuth:  cfg.Host.BasicAuth,
-		Insecure:   true,
-		HTTPClient: &http.Client{Transport: transport},
+		Endpoint:  endpoint,
+		BasicAuth: cfg.Host.BasicAuth,
+		Insecure:  cfg.Host.TLS.InsecureSkipVerify,
+		HTTPClient: &http.Client{
+			Transport: newLimitedTransport(instrumented, maxConcurrent, cfg.Host.RequestsPerSecond),
+		},
+		// The limit is enforced by the transport, which is shared between
+		// all clients derived with WithContext. Their gofish semaphore is
+		// shared as well, but waiting for it is not bound to their context,
+		// so it is sized to never block.
+		MaxConcurrentRequests: clientSemaphore,
 	}
 
-	return &config
+	return &config, nil
 }
 
+// clientSemaphore is the number of requests gofish lets a client and the
+// clients derived from it send at once.
+const clientSemaphore = 1024
+
 type Client struct {
 	*gofish.APIClient
+	config *gofish.ClientConfig
+	auth   *authenticator
 }
 
-func NewClient(logger *log.Logger, clientConfig *gofish.ClientConfig) (*Client, error) {
+func NewClient(logger *log.Logger, cfg config.Config, clientConfig *gofish.ClientConfig) (*Client, error) {
 	logger.Debug("Connecting to redfish service", zap.String("endpoint", clientConfig.Endpoint))
 
-	client, err := gofish.Connect(*clientConfig)
+	auth := newAuthenticator(cfg.Host)
+	httpClient := *clientConfig.HTTPClient
+	httpClient.Transport = auth.transport(httpClient.Transport)
+	config := *clientConfig
+	config.HTTPClient = &httpClient
+
+	client, err := auth.connect(context.Background(), config)
 	if err != nil {
 		logger.Error("Failed to connect to redfish service", zap.String("endpoint", clientConfig.Endpoint), zap.Error(err))
 		return nil, err
 	}
 
-	return &Client{client}, nil
+	return &Client{client, &config, auth}, nil
+}
+
+// WithContext returns a client whose requests, including those made by the
+// entities it returns, are bound to ctx. It reuses the session, transport
+// and service root of c, and only logs in again with ctx once the session
+// expired.
+func (c *Client) WithContext(ctx context.Context) (*Client, error) {
+	client, err := c.auth.connect(ctx, *c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{bind(client, ctx), c.config, c.auth}, nil
+}
+
+// Chassis returns a copy of chassis whose requests, including those of the
+// entities it returns, are sent by c, e.g. bound to another context.
+func (c *Client) Chassis(chassis *Chassis) *Chassis {
+	bound := *chassis
+	bound.SetClient(c.APIClient)
+	return &bound
+}
+
+// Logout deletes the current session, which may have been created by any of
+// the clients derived from c.
+func (c *Client) Logout() {
+	session := c.auth.currentSession()
+	if session == nil {
+		return
+	}
+
+	config := *c.config
+	config.Session = session
+	client, err := gofish.Connect(config)
+	if err != nil {
+		return
+	}
+	client.Logout()
 }
 
+// Start deletes the session on stop, after the event subscription was
+// deleted.
 func Start(client *Client, lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
-			client.APIClient.Logout()
+			client.Logout()
 			return nil
 		},
 	})
@@ -0,0 +1,182 @@
+package redfish
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	minStreamBackoff = time.Second
+	maxStreamBackoff = time.Minute
+
+	maxStreamLine = 1 << 20
+)
+
+// EventStream reads the events of the BMC from the Server-Sent Events stream
+// of its EventService. The exporter connects to the BMC, so unlike with a
+// subscription the BMC need not be able to reach the exporter. The stream is
+// reopened with exponential backoff when it breaks, resuming after the last
+// event received.
+type EventStream struct {
+	client  *Client
+	metrics *EventMetrics
+	logger  *log.Logger
+	log     bool
+
+	lastEventID string
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+func NewEventStream(client *Client, metrics *EventMetrics, cfg config.Config, logger *log.Logger) *EventStream {
+	return &EventStream{
+		client:  client,
+		metrics: metrics,
+		logger:  logger,
+		log:     cfg.Events.Log,
+		done:    make(chan struct{}),
+	}
+}
+
+// StartEventStream keeps the stream open while the app runs when events are
+// enabled in sse mode.
+func StartEventStream(stream *EventStream, cfg config.Config, lc fx.Lifecycle) {
+	if !cfg.Events.Enabled || cfg.Events.Mode != EventModeSSE {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			stream.cancel = cancel
+			go stream.run(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stream.cancel()
+			select {
+			case <-stream.done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+func (s *EventStream) run(ctx context.Context) {
+	defer close(s.done)
+
+	backoff := minStreamBackoff
+	for {
+		received, err := s.read(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// A stream that delivered anything was healthy, so the backoff
+		// starts over instead of growing with every reconnect.
+		if received {
+			backoff = minStreamBackoff
+		}
+		s.logger.Warn("Event stream closed, reconnecting", zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxStreamBackoff)
+	}
+}
+
+// read opens the stream and handles its events until it ends. It reports
+// whether anything was received.
+func (s *EventStream) read(ctx context.Context) (bool, error) {
+	// The stream stays open indefinitely and must not hold a slot that
+	// scrapes are waiting for.
+	client, err := s.client.WithContext(withoutLimit(ctx))
+	if err != nil {
+		return false, err
+	}
+	service, err := client.GetService().EventService()
+	if err != nil {
+		return false, err
+	}
+	if service.ServerSentEventURI == "" {
+		return false, errors.New("the BMC does not support Server-Sent Events")
+	}
+
+	headers := map[string]string{"Accept": "text/event-stream"}
+	if s.lastEventID != "" {
+		headers["Last-Event-ID"] = s.lastEventID
+	}
+	resp, err := client.GetWithHeaders(service.ServerSentEventURI, headers)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	s.logger.Info("Reading events from stream", zap.String("uri", service.ServerSentEventURI), zap.String("last_event_id", s.lastEventID))
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, maxStreamLine)
+	var id string
+	var data strings.Builder
+	hasID := false
+	for scanner.Scan() {
+		received = true
+		line := scanner.Text()
+		if line == "" {
+			if hasID {
+				s.lastEventID = id
+				hasID = false
+			}
+			if data.Len() > 0 {
+				s.handle(strings.TrimSuffix(data.String(), "\n"))
+				data.Reset()
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id, hasID = value, true
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, io.EOF
+}
+
+// handle counts the event in data, ignoring other payloads such as metric
+// reports.
+func (s *EventStream) handle(data string) {
+	event := &Event{}
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		s.logger.Warn("Failed to decode event from stream", zap.Error(err))
+		return
+	}
+	if len(event.Events) == 0 {
+		return
+	}
+
+	s.metrics.Record(event)
+	if s.log {
+		LogEvent(s.logger, event)
+	}
+}
@@ -0,0 +1,112 @@
+package redfish_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func event(messageID, severity string) redfishtest.Resource {
+	return redfishtest.Resource{
+		"@odata.type": "#Event.v1_7_0.Event",
+		"Id":          "1",
+		"Name":        "Event Array",
+		"Events": []interface{}{
+			redfishtest.Resource{
+				"EventType":       "Alert",
+				"MessageId":       messageID,
+				"MessageSeverity": severity,
+			},
+		},
+	}
+}
+
+// waitForEvents waits until the events counted in reg match expected.
+func waitForEvents(t *testing.T, reg *prometheus.Registry, expected string) {
+	t.Helper()
+
+	var err error
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "redfish_events_total"); err == nil {
+			return
+		}
+	}
+	t.Fatal(err)
+}
+
+func TestEventStream(t *testing.T) {
+	bmc := redfishtest.NewServer(redfishtest.DefaultTree())
+	t.Cleanup(bmc.Close)
+
+	cfg := config.Config{
+		Host: config.Host{
+			Endpoint: bmc.URL,
+			Username: bmc.Username,
+			Password: bmc.Password,
+			// The stream must not hold the only slot.
+			MaxConcurrentRequests: 1,
+		},
+		Events: config.Events{Enabled: true, Mode: redfish.EventModeSSE},
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := redfish.NewEventMetrics(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.Collectors()...)
+
+	lc := fxtest.NewLifecycle(t)
+	redfish.StartEventStream(redfish.NewEventStream(client, metrics, cfg, logger), cfg, lc)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	target := strings.TrimPrefix(bmc.URL, "http://")
+	header := `
+# HELP redfish_events_total Number of events sent by the BMC by severity and message, without the version of the message registry
+# TYPE redfish_events_total counter
+`
+	detected := fmt.Sprintf(`redfish_events_total{message_id="ResourceEvent.ResourceErrorsDetected",severity="Warning",target=%q} 1
+`, target)
+	corrected := fmt.Sprintf(`redfish_events_total{message_id="ResourceEvent.ResourceErrorsCorrected",severity="OK",target=%q} 1
+`, target)
+
+	if err := bmc.SendEvent(event("ResourceEvent.1.0.ResourceErrorsDetected", "Warning")); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvents(t, reg, header+detected)
+
+	if _, err := client.GetService().Chassis(); err != nil {
+		t.Errorf("expected requests to pass while the stream is open: %v", err)
+	}
+
+	// The event sent while disconnected is received after reconnecting,
+	// the one received before is not received again.
+	bmc.DisconnectStreams()
+	if err := bmc.SendEvent(event("ResourceEvent.1.0.ResourceErrorsCorrected", "OK")); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvents(t, reg, header+corrected+detected)
+
+	if got := bmc.Requests(redfishtest.EventStream); got != 2 {
+		t.Errorf("expected the stream to be opened twice, got %d", got)
+	}
+}
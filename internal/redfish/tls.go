@@ -0,0 +1,152 @@
+package redfish
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// legacyCipherSuites are offered on top of the secure defaults when legacy
+// ciphers are enabled, for BMCs that only speak TLS 1.0 with RC4 or 3DES.
+var legacyCipherSuites = []uint16{
+	// TLS 1.0 - 1.2 cipher suites.
+	tls.TLS_RSA_WITH_RC4_128_SHA,
+	tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	// TLS 1.3 cipher suites.
+	tls.TLS_AES_128_GCM_SHA256,
+	tls.TLS_AES_256_GCM_SHA384,
+	tls.TLS_CHACHA20_POLY1305_SHA256,
+}
+
+func newTLSConfig(cfg config.TLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		ServerName:         cfg.ServerName,
+	}
+
+	if cfg.LegacyCiphers {
+		tlsConfig.MinVersion = tls.VersionTLS10
+		tlsConfig.CipherSuites = legacyCipherSuites
+	}
+
+	if cfg.MinVersion != "" {
+		version, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q", cfg.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if cfg.MaxVersion != "" {
+		version, ok := tlsVersions[cfg.MaxVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q", cfg.MaxVersion)
+		}
+		tlsConfig.MaxVersion = version
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, errors.New("both certFile and keyFile are required for client certificates")
+		}
+
+		cert, err := newClientCertificate(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = cert.GetClientCertificate
+	}
+
+	if len(cfg.PinnedSHA256) > 0 {
+		pins, err := parsePins(cfg.PinnedSHA256)
+		if err != nil {
+			return nil, err
+		}
+
+		// A pinned certificate is trusted on its own, which is what makes
+		// pinning useful for self-signed BMC certificates, so the chain is
+		// not verified.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = verifyPins(pins)
+	}
+
+	return tlsConfig, nil
+}
+
+// parsePins decodes SHA-256 fingerprints written as hex, optionally
+// separated by colons as printed by openssl.
+func parsePins(fingerprints []string) ([][]byte, error) {
+	pins := make([][]byte, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		pin, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil || len(pin) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+		}
+		pins = append(pins, pin)
+	}
+	return pins, nil
+}
+
+func verifyPins(pins [][]byte) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("no certificate presented")
+		}
+
+		sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+		for _, pin := range pins {
+			if bytes.Equal(sum[:], pin) {
+				return nil
+			}
+		}
+		return fmt.Errorf("certificate fingerprint %s is not pinned", hex.EncodeToString(sum[:]))
+	}
+}
@@ -0,0 +1,79 @@
+package redfish
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/FreekingDean/redfish_exporter/internal/tracing"
+	"github.com/FreekingDean/redfish_exporter/internal/transport"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingTransport creates a span for every request sent to the BMC, named
+// by the method and path template. The span ends once the body is read or
+// closed, so it covers the whole transfer and records its size.
+func tracingTransport(tracer trace.Tracer, next http.RoundTripper) http.RoundTripper {
+	return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		path := pathTemplate(req.URL.Path)
+		ctx, span := tracer.Start(req.Context(), req.Method+" "+path,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.URLPath(req.URL.Path),
+				semconv.URLTemplate(path),
+				semconv.ServerAddress(req.URL.Hostname()),
+			),
+		)
+		if !span.IsRecording() {
+			span.End()
+			return next.RoundTrip(req)
+		}
+
+		resp, err := next.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			tracing.Fail(span, err)
+			span.End()
+			return resp, err
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", resp.StatusCode))
+		}
+		resp.Body = &tracedBody{ReadCloser: resp.Body, span: span}
+		return resp, nil
+	})
+}
+
+type tracedBody struct {
+	io.ReadCloser
+	span  trace.Span
+	bytes int
+	once  sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += n
+	if err == io.EOF {
+		b.end()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end()
+	return err
+}
+
+func (b *tracedBody) end() {
+	b.once.Do(func() {
+		b.span.SetAttributes(semconv.HTTPResponseBodySize(b.bytes))
+		b.span.End()
+	})
+}
@@ -0,0 +1,86 @@
+package redfishtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const fixtureFile = "index.json"
+
+// LoadFixture reads a Redfish tree from dir, laid out like the DMTF public
+// mockups: the response for a URI is stored in index.json in the directory
+// of the same path, e.g. dir/redfish/v1/Chassis/1U/index.json. Mockups in
+// the short form, where dir itself is the service root, are accepted too.
+func LoadFixture(dir string) (map[string]Resource, error) {
+	base := "/"
+	if _, err := os.Stat(filepath.Join(dir, "redfish", "v1", fixtureFile)); errors.Is(err, fs.ErrNotExist) {
+		base = ServiceRoot
+	}
+
+	resources := make(map[string]Resource)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != fixtureFile {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+		resource, err := readResource(file)
+		if err != nil {
+			return err
+		}
+		resources[normalize(path.Join(base, filepath.ToSlash(rel)))] = resource
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := resources[normalize(ServiceRoot)]; !ok {
+		return nil, fmt.Errorf("%s: no service root", dir)
+	}
+	return resources, nil
+}
+
+// WriteFixture writes resources to dir in the layout read by LoadFixture.
+func WriteFixture(dir string, resources map[string]Resource) error {
+	for uri, resource := range resources {
+		body, err := json.MarshalIndent(resource, "", "    ")
+		if err != nil {
+			return fmt.Errorf("%s: %w", uri, err)
+		}
+
+		// Cleaning the URI keeps .. segments reported by the BMC inside dir.
+		resourceDir := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(path.Clean("/"+uri), "/")))
+		if err := os.MkdirAll(resourceDir, 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(resourceDir, fixtureFile), append(body, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readResource(file string) (Resource, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var resource Resource
+	decoder := json.NewDecoder(f)
+	// Keep numbers as they were captured, e.g. large byte counters.
+	decoder.UseNumber()
+	if err := decoder.Decode(&resource); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return resource, nil
+}
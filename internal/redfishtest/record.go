@@ -0,0 +1,135 @@
+package redfishtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/FreekingDean/redfish_exporter/internal/transport"
+)
+
+// Recorder captures the Redfish resources fetched through its transport so
+// they can be written as a fixture and replayed.
+type Recorder struct {
+	mu        sync.Mutex
+	resources map[string]Resource
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		resources: make(map[string]Resource),
+	}
+}
+
+// Transport records the JSON body of every successful GET request sent
+// through next. Resources are keyed by their URI below the endpoint's path
+// prefix, so a BMC recorded behind a reverse proxy replays from the
+// service root.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		var resource Resource
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if decoder.Decode(&resource) == nil {
+			r.mu.Lock()
+			r.resources[serviceURI(req.URL.Path)] = resource
+			r.mu.Unlock()
+		}
+		return resp, nil
+	})
+}
+
+// Crawl fetches every resource linked from the service root, following
+// links up to depth levels deep. Resources that fail to load are skipped.
+// get is expected to send its requests through the recorder's transport,
+// e.g. gofish.APIClient.Get.
+func (r *Recorder) Crawl(get func(uri string) (*http.Response, error), depth int) {
+	seen := map[string]bool{normalize(ServiceRoot): true}
+	level := []string{ServiceRoot}
+	for i := 0; i <= depth && len(level) > 0; i++ {
+		var next []string
+		for _, uri := range level {
+			resp, err := get(uri)
+			if err != nil {
+				continue
+			}
+			resp.Body.Close()
+
+			r.mu.Lock()
+			resource := r.resources[normalize(uri)]
+			r.mu.Unlock()
+			for _, link := range links(resource) {
+				if !seen[link] {
+					seen[link] = true
+					next = append(next, link)
+				}
+			}
+		}
+		level = next
+	}
+}
+
+// Resources returns the resources recorded so far keyed by URI.
+func (r *Recorder) Resources() map[string]Resource {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resources := make(map[string]Resource, len(r.resources))
+	for uri, resource := range r.resources {
+		resources[uri] = resource
+	}
+	return resources
+}
+
+// links returns the URIs of the resources referenced by v. References to
+// members of a resource, such as Thermal#/Fans/0, and links outside of the
+// service are skipped.
+func links(v interface{}) []string {
+	var result []string
+	switch v := v.(type) {
+	case Resource:
+		for key, value := range v {
+			if uri, ok := value.(string); ok && key == "@odata.id" {
+				if uri := serviceURI(uri); strings.HasPrefix(uri, normalize(ServiceRoot)) && !strings.Contains(uri, "#") {
+					result = append(result, uri)
+				}
+				continue
+			}
+			result = append(result, links(value)...)
+		}
+	case map[string]interface{}:
+		return links(Resource(v))
+	case []interface{}:
+		for _, value := range v {
+			result = append(result, links(value)...)
+		}
+	}
+	return result
+}
+
+// serviceURI strips the path prefix of the endpoint from uri, i.e.
+// everything before its /redfish/v1 segment.
+func serviceURI(uri string) string {
+	segments := strings.Split(uri, "/")
+	for i := 1; i+1 < len(segments); i++ {
+		if segments[i] == "redfish" && segments[i+1] == "v1" {
+			return normalize("/" + strings.Join(segments[i:], "/"))
+		}
+	}
+	return normalize(uri)
+}
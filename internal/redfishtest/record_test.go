@@ -0,0 +1,125 @@
+package redfishtest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+)
+
+// record crawls tree served below prefix and returns the sanitized
+// recording.
+func record(t *testing.T, tree map[string]redfishtest.Resource, prefix string) map[string]redfishtest.Resource {
+	t.Helper()
+
+	server := redfishtest.NewServer(tree)
+	defer server.Close()
+	proxy := httptest.NewServer(http.StripPrefix(prefix, server.Config.Handler))
+	defer proxy.Close()
+
+	recorder := redfishtest.NewRecorder()
+	client := &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+	recorder.Crawl(func(uri string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, proxy.URL+prefix+uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(server.Username, server.Password)
+		return client.Do(req)
+	}, 8)
+
+	dir := t.TempDir()
+	if err := redfishtest.WriteFixture(dir, redfishtest.Sanitize(recorder.Resources())); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := redfishtest.LoadFixture(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+func TestRecord(t *testing.T) {
+	tree := redfishtest.DefaultTree()
+	tree["/redfish/v1/Chassis/1U"]["SerialNumber"] = "CN7475123J0042"
+	tree["/redfish/v1/Chassis/1U"]["Id"] = "CN7475123J0042"
+	tree["/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts/1"]["AssociatedNetworkAddresses"] = []interface{}{"B0:26:28:AA:01:02"}
+	tree["/redfish/v1/Chassis/1U/NetworkAdapters/NIC1"]["Description"] = "Uplink to 10.20.30.40"
+	fixture := record(t, tree, "")
+
+	if len(fixture) != len(tree) {
+		t.Errorf("expected %d resources, got %d", len(tree), len(fixture))
+	}
+	body, err := json.Marshal(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"CN7475123J0042", "B0:26:28:AA:01:02", "10.20.30.40"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("recording contains %s", secret)
+		}
+	}
+	if id := fixture["/redfish/v1/Chassis/1U"]["Id"]; id != "SERIAL0001" {
+		t.Errorf("expected the serial number in Id to be replaced consistently, got %v", id)
+	}
+}
+
+func TestRecordPathPrefix(t *testing.T) {
+	tree := redfishtest.DefaultTree()
+	fixture := record(t, tree, "/bmc/1")
+
+	if len(fixture) != len(tree) {
+		t.Errorf("expected %d resources, got %d", len(tree), len(fixture))
+	}
+	if _, ok := fixture["/redfish/v1/Chassis/1U"]; !ok {
+		t.Errorf("expected resources to be recorded below the service root, got %v", fixture)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	resources := map[string]redfishtest.Resource{
+		"/redfish/v1/Chassis/1000": {
+			"@odata.id":       "/redfish/v1/Chassis/1000",
+			"Id":              "1000",
+			"SerialNumber":    "1000",
+			"FirmwareVersion": "2.1000.3",
+			"Description":     "Chassis 1000 of rack 10001",
+			"PartNumber":      "PN-1000",
+		},
+	}
+	var addresses []interface{}
+	for i := 0; i < 300; i++ {
+		addresses = append(addresses, fmt.Sprintf("10.0.%d.%d", i/250, i%250+1))
+	}
+	resources["/redfish/v1/Managers/BMC"] = redfishtest.Resource{"Addresses": addresses}
+
+	sanitized := redfishtest.Sanitize(resources)
+	chassis, ok := sanitized["/redfish/v1/Chassis/SERIAL0001"]
+	if !ok {
+		t.Fatalf("expected the serial number in the URI to be replaced, got %v", sanitized)
+	}
+	for key, expected := range map[string]string{
+		"@odata.id":       "/redfish/v1/Chassis/SERIAL0001",
+		"Id":              "SERIAL0001",
+		"SerialNumber":    "SERIAL0001",
+		"FirmwareVersion": "2.1000.3",
+		"Description":     "Chassis SERIAL0001 of rack 10001",
+		"PartNumber":      "PN-1000",
+	} {
+		if chassis[key] != expected {
+			t.Errorf("expected %s to be %q, got %q", key, expected, chassis[key])
+		}
+	}
+
+	seen := make(map[interface{}]bool)
+	for _, address := range sanitized["/redfish/v1/Managers/BMC"]["Addresses"].([]interface{}) {
+		if seen[address] {
+			t.Errorf("address %v replaces more than one address", address)
+		}
+		seen[address] = true
+	}
+}
@@ -0,0 +1,167 @@
+package redfishtest
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	macPattern  = regexp.MustCompile(`\b[0-9A-Fa-f]{2}([:-])(?:[0-9A-Fa-f]{2}[:-]){4}[0-9A-Fa-f]{2}\b`)
+	ipv4Pattern = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\b`)
+	// tokenPattern matches the words of a string that a serial number may
+	// be used as, e.g. the segments of a URI. Dots and dashes are part of a
+	// token, so a short serial number doesn't match part of a version.
+	tokenPattern = regexp.MustCompile(`[^\s/,;:()\[\]"'=#]+`)
+
+	// placeholders are values BMCs report instead of a serial number.
+	placeholders = map[string]bool{
+		"":                       true,
+		"n/a":                    true,
+		"na":                     true,
+		"none":                   true,
+		"unknown":                true,
+		"not specified":          true,
+		"not available":          true,
+		"to be filled by o.e.m.": true,
+	}
+)
+
+// Sanitize replaces serial numbers, MAC addresses and IP addresses in
+// resources and their URIs with made up values. Every occurrence of a value
+// gets the same replacement, also where it is a token of another string
+// such as a URI, so links stay intact. Values are only replaced as a whole,
+// never where they are part of a longer word or number.
+func Sanitize(resources map[string]Resource) map[string]Resource {
+	// Values are numbered in order of their URI and key, so recording the
+	// same tree twice gives the same result.
+	s := &sanitizer{replacements: make(map[string]string)}
+	for _, uri := range sortedKeys(resources) {
+		s.collect("", resources[uri])
+	}
+
+	sanitized := make(map[string]Resource, len(resources))
+	for uri, resource := range resources {
+		sanitized[s.replace(uri)] = s.sanitize(resource).(Resource)
+	}
+	return sanitized
+}
+
+type sanitizer struct {
+	replacements map[string]string
+	serials      int
+	macs         int
+	ips          int
+}
+
+func (s *sanitizer) collect(key string, v interface{}) {
+	switch v := v.(type) {
+	case Resource:
+		for _, key := range sortedKeys(v) {
+			s.collect(key, v[key])
+		}
+	case map[string]interface{}:
+		s.collect(key, Resource(v))
+	case []interface{}:
+		for _, value := range v {
+			s.collect(key, value)
+		}
+	case string:
+		if isSerialKey(key) && len(v) >= 4 && !placeholders[strings.ToLower(strings.TrimSpace(v))] {
+			s.add(v, func() string {
+				s.serials++
+				return fmt.Sprintf("SERIAL%04d", s.serials)
+			})
+		}
+		for _, mac := range macPattern.FindAllString(v, -1) {
+			if strings.Trim(mac, "0:-") == "" {
+				continue
+			}
+			s.add(mac, func() string {
+				s.macs++
+				return fmt.Sprintf("02:00:%02x:%02x:%02x:%02x", s.macs>>24&0xff, s.macs>>16&0xff, s.macs>>8&0xff, s.macs&0xff)
+			})
+		}
+		for _, ip := range ipv4Pattern.FindAllString(v, -1) {
+			if parsed := net.ParseIP(ip); parsed.IsUnspecified() || parsed.IsLoopback() || strings.HasPrefix(ip, "255.") {
+				continue
+			}
+			s.add(ip, func() string {
+				s.ips++
+				return ipv4(s.ips)
+			})
+		}
+		if ip := net.ParseIP(v); ip != nil && ip.To4() == nil && !ip.IsUnspecified() && !ip.IsLoopback() {
+			s.add(v, func() string {
+				s.ips++
+				return fmt.Sprintf("2001:db8::%x", s.ips)
+			})
+		}
+	}
+}
+
+func (s *sanitizer) add(value string, replacement func() string) {
+	if _, ok := s.replacements[value]; !ok {
+		s.replacements[value] = replacement()
+	}
+}
+
+// replace replaces v if it is a collected value as a whole, and otherwise
+// the MAC addresses, IP addresses and serial numbers among its tokens.
+func (s *sanitizer) replace(v string) string {
+	if replacement, ok := s.replacements[v]; ok {
+		return replacement
+	}
+	lookup := func(value string) string {
+		if replacement, ok := s.replacements[value]; ok {
+			return replacement
+		}
+		return value
+	}
+	v = macPattern.ReplaceAllStringFunc(v, lookup)
+	v = ipv4Pattern.ReplaceAllStringFunc(v, lookup)
+	return tokenPattern.ReplaceAllStringFunc(v, lookup)
+}
+
+func (s *sanitizer) sanitize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case Resource:
+		result := make(Resource, len(v))
+		for key, value := range v {
+			result[key] = s.sanitize(value)
+		}
+		return result
+	case map[string]interface{}:
+		return map[string]interface{}(s.sanitize(Resource(v)).(Resource))
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = s.sanitize(value)
+		}
+		return result
+	case string:
+		return s.replace(v)
+	}
+	return v
+}
+
+// ipv4 returns the nth address of the 198.18.0.0/15 benchmarking range,
+// which holds more addresses than a BMC reports.
+func ipv4(n int) string {
+	return fmt.Sprintf("198.%d.%d.%d", 18+n>>16, n>>8&0xff, n&0xff)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isSerialKey(key string) bool {
+	return strings.HasSuffix(key, "SerialNumber") || key == "ServiceTag"
+}
@@ -0,0 +1,413 @@
+// Package redfishtest serves a configurable Redfish tree over HTTP, for tests
+// and for running the exporter without a BMC.
+package redfishtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ServiceRoot   = "/redfish/v1/"
+	Sessions      = "/redfish/v1/SessionService/Sessions"
+	Subscriptions = "/redfish/v1/EventService/Subscriptions"
+	EventStream   = "/redfish/v1/EventService/SSE"
+
+	DefaultUsername = "admin"
+	DefaultPassword = "password"
+)
+
+// Resource is the JSON body of a Redfish resource.
+type Resource map[string]interface{}
+
+// Fault is injected into the responses for a URI.
+type Fault struct {
+	// Latency delays the response.
+	Latency time.Duration
+	// Status responds with this status code instead of the resource.
+	Status int
+	// Malformed responds with a truncated JSON body.
+	Malformed bool
+}
+
+// Server serves a Redfish tree. Requests other than for the service root
+// and creating a session must authenticate, either with basic auth or with a
+// session token. Members can be added to collections with POST and removed
+// with DELETE.
+type Server struct {
+	*httptest.Server
+
+	Username string
+	Password string
+
+	mu        sync.Mutex
+	resources map[string]Resource
+	faults    map[string]Fault
+	sessions  map[string]bool
+	requests  map[string]int
+
+	// events are sent on the event stream with their index plus one as ID.
+	events [][]byte
+	// changed is closed and replaced when an event is sent or the streams
+	// are disconnected, which increments generation.
+	changed    chan struct{}
+	generation int
+}
+
+// NewServer starts a server serving resources, e.g. DefaultTree(). It must
+// be closed by the caller.
+func NewServer(resources map[string]Resource) *Server {
+	s := &Server{
+		Username:  DefaultUsername,
+		Password:  DefaultPassword,
+		resources: make(map[string]Resource, len(resources)),
+		faults:    make(map[string]Fault),
+		sessions:  make(map[string]bool),
+		requests:  make(map[string]int),
+		changed:   make(chan struct{}),
+	}
+	for uri, resource := range resources {
+		s.resources[normalize(uri)] = resource
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close disconnects the event streams, which would otherwise keep the server
+// from shutting down, and shuts it down.
+func (s *Server) Close() {
+	s.DisconnectStreams()
+	s.Server.Close()
+}
+
+// Set replaces the resource at uri, or removes it if resource is nil.
+func (s *Server) Set(uri string, resource Resource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resource == nil {
+		delete(s.resources, normalize(uri))
+		return
+	}
+	s.resources[normalize(uri)] = resource
+}
+
+// Fault injects f into every response for uri.
+func (s *Server) Fault(uri string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[normalize(uri)] = f
+}
+
+// ExpireSessions invalidates all sessions, requests using them are answered
+// with 401 until a new session is created.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// SessionCount returns the number of sessions created so far.
+func (s *Server) SessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests["POST "+Sessions]
+}
+
+// SendEvent sends event to the clients of the event stream. Clients that
+// connect later receive it unless they resume after it with Last-Event-ID.
+func (s *Server) SendEvent(event Resource) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, body)
+	s.notify()
+	return nil
+}
+
+// DisconnectStreams closes the connections of all event stream clients.
+func (s *Server) DisconnectStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	s.notify()
+}
+
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Requests returns the number of requests made for uri.
+func (s *Server) Requests(uri string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[normalize(uri)]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	uri := normalize(r.URL.Path)
+
+	s.mu.Lock()
+	if r.Method == http.MethodPost {
+		s.requests[r.Method+" "+uri]++
+	} else {
+		s.requests[uri]++
+	}
+	fault := s.faults[uri]
+	s.mu.Unlock()
+
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault.Status != 0 {
+		http.Error(w, http.StatusText(fault.Status), fault.Status)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && uri == Sessions:
+		s.createSession(w, r)
+		return
+	case r.Method == http.MethodDelete && strings.HasPrefix(uri, Sessions+"/"):
+		s.deleteSession(w, r, uri)
+		return
+	}
+
+	if uri != normalize(ServiceRoot) && !s.authenticated(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if uri == EventStream {
+			s.streamEvents(w, r)
+			return
+		}
+	case http.MethodPost:
+		s.createMember(w, r, uri)
+		return
+	case http.MethodDelete:
+		s.deleteMember(w, r, uri)
+		return
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	resource, ok := s.resources[uri]
+	var body []byte
+	var err error
+	if ok {
+		body, err = json.Marshal(resource)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if fault.Malformed {
+		body = body[:len(body)/2]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var credentials struct {
+		UserName string
+		Password string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if credentials.UserName != s.Username || credentials.Password != s.Password {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	token := fmt.Sprintf("token-%d", s.requests["POST "+Sessions])
+	s.sessions[token] = true
+	s.mu.Unlock()
+
+	uri := Sessions + "/" + token
+	w.Header().Set("X-Auth-Token", token)
+	w.Header().Set("Location", uri)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Resource{"@odata.id": uri, "Id": token})
+}
+
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request, uri string) {
+	s.mu.Lock()
+	delete(s.sessions, strings.TrimPrefix(uri, Sessions+"/"))
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// streamEvents sends the events after Last-Event-ID, and those sent later
+// until the client or DisconnectStreams closes the stream.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	sent, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": stream opened\n\n")
+
+	s.mu.Lock()
+	generation := s.generation
+	s.mu.Unlock()
+	for {
+		s.mu.Lock()
+		events := s.events[min(sent, len(s.events)):]
+		changed := s.changed
+		disconnected := s.generation != generation
+		s.mu.Unlock()
+		if disconnected {
+			return
+		}
+
+		for _, event := range events {
+			sent++
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", sent, event)
+		}
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) createMember(w http.ResponseWriter, r *http.Request, collection string) {
+	member := Resource{}
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	resource, ok := s.resources[collection]
+	members, isCollection := resource["Members"].([]interface{})
+	if !ok || !isCollection {
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	id := fmt.Sprint(s.requests["POST "+collection])
+	uri := collection + "/" + id
+	member["@odata.id"] = uri
+	member["Id"] = id
+	s.resources[uri] = member
+	resource["Members"] = append(members, Link(uri))
+	resource["Members@odata.count"] = len(members) + 1
+	s.mu.Unlock()
+
+	w.Header().Set("Location", uri)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+func (s *Server) deleteMember(w http.ResponseWriter, r *http.Request, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	collection := s.resources[path.Dir(uri)]
+	members, isCollection := collection["Members"].([]interface{})
+	if _, ok := s.resources[uri]; !ok || !isCollection {
+		http.NotFound(w, r)
+		return
+	}
+
+	delete(s.resources, uri)
+	remaining := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		if linkTarget(member) != uri {
+			remaining = append(remaining, member)
+		}
+	}
+	collection["Members"] = remaining
+	collection["Members@odata.count"] = len(remaining)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Members returns the members of the collection at uri.
+func (s *Server) Members(uri string) []Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, _ := s.resources[normalize(uri)]["Members"].([]interface{})
+	result := make([]Resource, 0, len(members))
+	for _, member := range members {
+		if resource, ok := s.resources[linkTarget(member)]; ok {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// linkTarget returns the normalized URI a link refers to, whether it was
+// created with Link or decoded from JSON.
+func linkTarget(link interface{}) string {
+	var id interface{}
+	switch link := link.(type) {
+	case Resource:
+		id = link["@odata.id"]
+	case map[string]interface{}:
+		id = link["@odata.id"]
+	}
+	uri, _ := id.(string)
+	return normalize(uri)
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	if token := r.Header.Get("X-Auth-Token"); token != "" {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.sessions[token]
+	}
+
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Basic ")
+	if !ok {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth)
+	return err == nil && string(decoded) == s.Username+":"+s.Password
+}
+
+// normalize strips the trailing slash so /redfish/v1 and /redfish/v1/ refer
+// to the same resource.
+func normalize(uri string) string {
+	if uri != "/" {
+		uri = strings.TrimSuffix(uri, "/")
+	}
+	return uri
+}
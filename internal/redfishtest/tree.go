@@ -0,0 +1,157 @@
+package redfishtest
+
+// Link returns a Redfish reference to uri.
+func Link(uri string) Resource {
+	return Resource{"@odata.id": uri}
+}
+
+// Collection returns a Redfish collection with the given members.
+func Collection(uri string, members ...string) Resource {
+	links := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		links = append(links, Link(member))
+	}
+	return Resource{
+		"@odata.id":           uri,
+		"Members":             links,
+		"Members@odata.count": len(members),
+	}
+}
+
+func status(health, state string) Resource {
+	return Resource{"Health": health, "State": state}
+}
+
+// DefaultTree returns a small but complete tree: one chassis with thermal,
+// power and network resources. Tests modify it to the case at hand.
+func DefaultTree() map[string]Resource {
+	return map[string]Resource{
+		ServiceRoot: {
+			"@odata.id":      "/redfish/v1/",
+			"Id":             "RootService",
+			"Name":           "Root Service",
+			"RedfishVersion": "1.15.0",
+			"Vendor":         "Contoso",
+			"Chassis":        Link("/redfish/v1/Chassis"),
+			"Systems":        Link("/redfish/v1/Systems"),
+			"SessionService": Link("/redfish/v1/SessionService"),
+			"EventService":   Link("/redfish/v1/EventService"),
+			"Links": Resource{
+				"Sessions": Link(Sessions),
+			},
+		},
+		"/redfish/v1/SessionService": {
+			"@odata.id": "/redfish/v1/SessionService",
+			"Id":        "SessionService",
+			"Sessions":  Link(Sessions),
+		},
+		Sessions: Collection(Sessions),
+		"/redfish/v1/EventService": {
+			"@odata.id":          "/redfish/v1/EventService",
+			"Id":                 "EventService",
+			"ServiceEnabled":     true,
+			"Subscriptions":      Link(Subscriptions),
+			"ServerSentEventUri": EventStream,
+		},
+		Subscriptions:         Collection(Subscriptions),
+		"/redfish/v1/Systems": Collection("/redfish/v1/Systems"),
+		"/redfish/v1/Chassis": Collection("/redfish/v1/Chassis", "/redfish/v1/Chassis/1U"),
+		"/redfish/v1/Chassis/1U": {
+			"@odata.id":       "/redfish/v1/Chassis/1U",
+			"Id":              "1U",
+			"Name":            "Computer System Chassis",
+			"ChassisType":     "RackMount",
+			"Manufacturer":    "Contoso",
+			"Model":           "3500RX",
+			"Status":          status("OK", "Enabled"),
+			"Thermal":         Link("/redfish/v1/Chassis/1U/Thermal"),
+			"Power":           Link("/redfish/v1/Chassis/1U/Power"),
+			"NetworkAdapters": Link("/redfish/v1/Chassis/1U/NetworkAdapters"),
+		},
+		"/redfish/v1/Chassis/1U/Thermal": {
+			"@odata.id": "/redfish/v1/Chassis/1U/Thermal",
+			"Id":        "Thermal",
+			"Name":      "Thermal",
+			"Temperatures": []interface{}{
+				Resource{
+					"MemberId":               "0",
+					"Name":                   "CPU1 Temp",
+					"ReadingCelsius":         41,
+					"UpperThresholdCritical": 90,
+					"UpperThresholdFatal":    95,
+					"Status":                 status("OK", "Enabled"),
+				},
+			},
+			"Fans": []interface{}{
+				Resource{
+					"MemberId":               "0",
+					"Name":                   "Fan 1",
+					"Reading":                4200,
+					"ReadingUnits":           "RPM",
+					"MinReadingRange":        0,
+					"MaxReadingRange":        8400,
+					"LowerThresholdCritical": 500,
+					"Status":                 status("OK", "Enabled"),
+				},
+			},
+		},
+		"/redfish/v1/Chassis/1U/Power": {
+			"@odata.id": "/redfish/v1/Chassis/1U/Power",
+			"Id":        "Power",
+			"Name":      "Power",
+			"PowerControl": []interface{}{
+				Resource{
+					"MemberId": "0",
+					"Name":     "System Power Control",
+					"PowerMetrics": Resource{
+						"AverageConsumedWatts": 319,
+					},
+				},
+			},
+			"Voltages": []interface{}{
+				Resource{
+					"MemberId":               "0",
+					"Name":                   "VRM1 Voltage",
+					"ReadingVolts":           12,
+					"UpperThresholdCritical": 13,
+					"LowerThresholdCritical": 11,
+					"Status":                 status("OK", "Enabled"),
+				},
+			},
+			"PowerSupplies": []interface{}{
+				Resource{
+					"MemberId":             "0",
+					"Name":                 "Power Supply Bay 1",
+					"PowerInputWatts":      340,
+					"PowerOutputWatts":     319,
+					"PowerCapacityWatts":   800,
+					"LastPowerOutputWatts": 319,
+					"EfficiencyPercent":    94,
+					"Status":               status("OK", "Enabled"),
+				},
+			},
+		},
+		"/redfish/v1/Chassis/1U/NetworkAdapters": Collection("/redfish/v1/Chassis/1U/NetworkAdapters",
+			"/redfish/v1/Chassis/1U/NetworkAdapters/NIC1"),
+		"/redfish/v1/Chassis/1U/NetworkAdapters/NIC1": {
+			"@odata.id":    "/redfish/v1/Chassis/1U/NetworkAdapters/NIC1",
+			"Id":           "NIC1",
+			"Name":         "Network Adapter 1",
+			"Status":       status("OK", "Enabled"),
+			"Metrics":      Resource{"TXBytes": 1000, "RXBytes": 2000},
+			"NetworkPorts": Link("/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts"),
+		},
+		"/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts": Collection("/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts",
+			"/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts/1"),
+		"/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts/1": {
+			"@odata.id":            "/redfish/v1/Chassis/1U/NetworkAdapters/NIC1/NetworkPorts/1",
+			"Id":                   "1",
+			"Name":                 "Port 1",
+			"PhysicalPortNumber":   "1",
+			"LinkStatus":           "Up",
+			"ActiveLinkTechnology": "Ethernet",
+			"CurrentLinkSpeedMbps": 10000,
+			"Status":               status("OK", "Enabled"),
+		},
+	}
+}
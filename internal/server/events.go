@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"go.uber.org/zap"
+)
+
+const (
+	eventsPath = "/events"
+
+	maxEventSize = 1 << 20
+)
+
+// RegisterEventReceiver accepts the events the BMC pushes to /events when
+// events are enabled in push mode. Events that do not carry the context of
+// the subscription are rejected.
+func RegisterEventReceiver(mux *http.ServeMux, subscription *redfish.EventSubscription, metrics *redfish.EventMetrics, cfg config.Config, logger *log.Logger) {
+	if !cfg.Events.Enabled || cfg.Events.Mode != redfish.EventModePush {
+		return
+	}
+
+	mux.HandleFunc(eventsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		event := &redfish.Event{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEventSize)).Decode(event); err != nil {
+			logger.Warn("Failed to decode event", zap.String("remote", r.RemoteAddr), zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !subscription.Verify(event) {
+			logger.Warn("Rejecting event with unknown context", zap.String("remote", r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		metrics.Record(event)
+		if cfg.Events.Log {
+			redfish.LogEvent(logger, event)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
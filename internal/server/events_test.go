@@ -0,0 +1,221 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"github.com/FreekingDean/redfish_exporter/internal/log"
+	"github.com/FreekingDean/redfish_exporter/internal/redfish"
+	"github.com/FreekingDean/redfish_exporter/internal/redfishtest"
+	"github.com/FreekingDean/redfish_exporter/internal/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const destination = "https://exporter.example.com:9610/events"
+
+func event(context string) string {
+	return fmt.Sprintf(`{
+  "@odata.type": "#Event.v1_7_0.Event",
+  "Id": "1",
+  "Name": "Event Array",
+  "Context": %q,
+  "Events": [
+    {
+      "EventType": "Alert",
+      "MessageId": "ResourceEvent.1.0.ResourceErrorsDetected",
+      "MessageSeverity": "Warning",
+      "Message": "The resource PSU1 has detected errors of type Input Voltage.",
+      "OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/1U/Power"}
+    },
+    {
+      "EventType": "Alert",
+      "MessageId": "ResourceEvent.1.0.ResourceErrorsCorrected",
+      "Severity": "OK",
+      "Message": "The resource PSU1 has corrected errors of type Input Voltage."
+    }
+  ]
+}`, context)
+}
+
+// legacyEvent is an event of Event v1.0, which carries the context in its
+// records.
+func legacyEvent(contexts ...string) string {
+	records := make([]string, 0, len(contexts))
+	for _, context := range contexts {
+		records = append(records, fmt.Sprintf(`{
+      "EventType": "Alert",
+      "MessageId": "ResourceEvent.1.0.ResourceErrorsDetected",
+      "Severity": "Warning",
+      "Message": "The resource PSU1 has detected errors of type Input Voltage.",
+      "Context": %q
+    }`, context))
+	}
+	return fmt.Sprintf(`{
+  "@odata.type": "#Event.v1_0_0.Event",
+  "Id": "1",
+  "Name": "Event Array",
+  "Events": [%s]
+}`, strings.Join(records, ","))
+}
+
+// newReceiver subscribes to the events of bmc and returns the mux receiving
+// them once lc is started.
+func newReceiver(t *testing.T, bmc *redfishtest.Server) (*http.ServeMux, *redfish.EventMetrics, *fxtest.Lifecycle) {
+	t.Helper()
+
+	cfg := config.Config{
+		Host: config.Host{
+			Endpoint: bmc.URL,
+			Username: bmc.Username,
+			Password: bmc.Password,
+		},
+		Events: config.Events{Enabled: true, Mode: redfish.EventModePush, Destination: destination},
+	}
+	logger := &log.Logger{Logger: zap.NewNop()}
+	clientConfig, err := redfish.NewClientConfig(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := redfish.NewClient(logger, cfg, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription, err := redfish.NewEventSubscription(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := redfish.NewEventMetrics(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lc := fxtest.NewLifecycle(t)
+	redfish.Start(client, lc)
+	redfish.Subscribe(client, subscription, metrics, cfg, logger, lc)
+	mux := http.NewServeMux()
+	server.RegisterEventReceiver(mux, subscription, metrics, cfg, logger)
+	return mux, metrics, lc
+}
+
+// waitForSubscription waits until the exporter subscribed to the events of
+// bmc, which happens in the background.
+func waitForSubscription(t *testing.T, bmc *redfishtest.Server) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		subscriptions := bmc.Members(redfishtest.Subscriptions)
+		if len(subscriptions) == 1 && subscriptions[0]["Context"] != "unknown" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for the subscription, got %v", bmc.Members(redfishtest.Subscriptions))
+}
+
+func TestEventReceiver(t *testing.T) {
+	bmc := redfishtest.NewServer(redfishtest.DefaultTree())
+	t.Cleanup(bmc.Close)
+	// A subscription left behind by an earlier run.
+	stale := redfishtest.Subscriptions + "/stale"
+	bmc.Set(stale, redfishtest.Resource{"@odata.id": stale, "Destination": destination, "Context": "unknown"})
+	bmc.Set(redfishtest.Subscriptions, redfishtest.Collection(redfishtest.Subscriptions, stale))
+
+	mux, metrics, lc := newReceiver(t, bmc)
+	lc.RequireStart()
+	waitForSubscription(t, bmc)
+
+	subscriptions := bmc.Members(redfishtest.Subscriptions)
+	if len(subscriptions) != 1 {
+		t.Fatalf("expected the stale subscription to be replaced, got %v", subscriptions)
+	}
+	if got := subscriptions[0]["Destination"]; got != destination {
+		t.Errorf("expected destination %s, got %v", destination, got)
+	}
+	context, _ := subscriptions[0]["Context"].(string)
+	if context == "" || context == "unknown" {
+		t.Fatalf("expected a new context, got %q", context)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		body     string
+		expected int
+	}{
+		{"valid", http.MethodPost, event(context), http.StatusOK},
+		{"unknown context", http.MethodPost, event("unknown"), http.StatusForbidden},
+		{"record context", http.MethodPost, legacyEvent(context), http.StatusOK},
+		{"unknown record context", http.MethodPost, legacyEvent(context, "unknown"), http.StatusForbidden},
+		{"no context", http.MethodPost, legacyEvent(), http.StatusForbidden},
+		{"malformed", http.MethodPost, event(context)[:20], http.StatusBadRequest},
+		{"get", http.MethodGet, "", http.StatusMethodNotAllowed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tc.method, "/events", strings.NewReader(tc.body)))
+			if w.Code != tc.expected {
+				t.Errorf("expected status %d, got %d", tc.expected, w.Code)
+			}
+		})
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.Collectors()...)
+	target := strings.TrimPrefix(bmc.URL, "http://")
+	expected := fmt.Sprintf(`
+# HELP redfish_events_total Number of events sent by the BMC by severity and message, without the version of the message registry
+# TYPE redfish_events_total counter
+redfish_events_total{message_id="ResourceEvent.ResourceErrorsCorrected",severity="OK",target=%[1]q} 1
+redfish_events_total{message_id="ResourceEvent.ResourceErrorsDetected",severity="Warning",target=%[1]q} 2
+`, target)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "redfish_events_total"); err != nil {
+		t.Error(err)
+	}
+	if count, err := testutil.GatherAndCount(reg, "redfish_last_event_timestamp_seconds"); err != nil || count != 1 {
+		t.Errorf("expected the last event timestamp, got %d series: %v", count, err)
+	}
+
+	lc.RequireStop()
+	if subscriptions := bmc.Members(redfishtest.Subscriptions); len(subscriptions) != 0 {
+		t.Errorf("expected the subscription to be deleted on stop, got %v", subscriptions)
+	}
+}
+
+func TestEventSubscriptionRetry(t *testing.T) {
+	bmc := redfishtest.NewServer(redfishtest.DefaultTree())
+	t.Cleanup(bmc.Close)
+	bmc.Fault(redfishtest.Subscriptions, redfishtest.Fault{Status: http.StatusServiceUnavailable})
+
+	_, metrics, lc := newReceiver(t, bmc)
+	// The exporter starts even though the BMC rejects the subscription.
+	lc.RequireStart()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.Collectors()...)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if count, _ := testutil.GatherAndCount(reg, "redfish_exporter_event_subscription_failures_total"); count > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the failed subscription to be counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	bmc.Fault(redfishtest.Subscriptions, redfishtest.Fault{})
+	waitForSubscription(t, bmc)
+
+	lc.RequireStop()
+	if subscriptions := bmc.Members(redfishtest.Subscriptions); len(subscriptions) != 0 {
+		t.Errorf("expected the subscription to be deleted on stop, got %v", subscriptions)
+	}
+}
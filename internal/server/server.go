@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/FreekingDean/redfish_exporter/internal/config"
@@ -23,18 +24,21 @@ func New(mux *http.ServeMux) *http.Server {
 	}
 }
 
+// Run starts the server. The listener is bound on start, so the hooks
+// started after Run can rely on the server accepting connections.
 func Run(server *http.Server, cfg config.Config, logger *log.Logger, lc fx.Lifecycle) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			addresses := []string{cfg.Web.ListenAddress()}
-
 			flagConfig := &web.FlagConfig{
-				WebListenAddresses: &addresses,
-				WebConfigFile:      &cfg.Web.ConfigFile,
+				WebConfigFile: &cfg.Web.ConfigFile,
 			}
 
+			listener, err := net.Listen("tcp", cfg.Web.ListenAddress())
+			if err != nil {
+				return err
+			}
 			go func() {
-				if err := web.ListenAndServe(server, flagConfig, logger.Slog()); err != nil {
+				if err := web.Serve(listener, server, flagConfig, logger.Slog()); err != nil {
 					if err != http.ErrServerClosed {
 						logger.Error("Failed to start server", zap.Error(err))
 					}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+)
+
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+
+	serviceName = "redfish_exporter"
+	tracerName  = "github.com/FreekingDean/redfish_exporter"
+)
+
+// New returns the tracer for scrapes and the requests they send to the BMC.
+// Spans are exported to the configured OTLP receiver and flushed when the app
+// stops. Without an endpoint the tracer records nothing.
+func New(cfg config.Config, lc fx.Lifecycle) (trace.Tracer, error) {
+	if cfg.Tracing.Endpoint == "" {
+		return noop.NewTracerProvider().Tracer(tracerName), nil
+	}
+
+	exporter, err := newExporter(cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio),
+		)),
+	)
+	lc.Append(fx.Hook{
+		OnStop: provider.Shutdown,
+	})
+
+	return provider.Tracer(tracerName), nil
+}
+
+// newExporter creates the exporter for the protocol. Neither connects before
+// the first spans are exported, so an unreachable receiver does not keep the
+// exporter from starting.
+func newExporter(cfg config.Tracing) (*otlptrace.Exporter, error) {
+	ctx := context.Background()
+	if cfg.Protocol == ProtocolHTTP {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// Fail marks span as failed with err.
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
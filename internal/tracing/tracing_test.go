@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FreekingDean/redfish_exporter/internal/config"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a local OTLP receiver that forwards the names of the spans it
+// receives.
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	spans chan string
+}
+
+func (c *collector) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	for _, resourceSpans := range req.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				c.spans <- span.GetName()
+			}
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, _ := c.Export(r.Context(), req)
+	body, _ = proto.Marshal(resp)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(body)
+}
+
+func (c *collector) listenGRPC(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, c)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func (c *collector) listenHTTP(t *testing.T) string {
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestNew(t *testing.T) {
+	for _, protocol := range []string{ProtocolGRPC, ProtocolHTTP} {
+		t.Run(protocol, func(t *testing.T) {
+			c := &collector{spans: make(chan string, 10)}
+			endpoint := c.listenHTTP(t)
+			if protocol == ProtocolGRPC {
+				endpoint = c.listenGRPC(t)
+			}
+
+			lc := fxtest.NewLifecycle(t)
+			tracer, err := New(config.Config{Tracing: config.Tracing{
+				Endpoint:    endpoint,
+				Protocol:    protocol,
+				Insecure:    true,
+				SampleRatio: 1,
+			}}, lc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lc.RequireStart()
+
+			_, span := tracer.Start(context.Background(), "scrape")
+			span.End()
+			// Stopping flushes the spans.
+			lc.RequireStop()
+
+			select {
+			case name := <-c.spans:
+				if name != "scrape" {
+					t.Errorf("expected span scrape, got %s", name)
+				}
+			default:
+				t.Error("no span was exported")
+			}
+		})
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	tracer, err := New(config.Config{}, fxtest.NewLifecycle(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, span := tracer.Start(context.Background(), "scrape"); span.IsRecording() {
+		t.Error("expected spans not to be recorded without an endpoint")
+	}
+}
@@ -0,0 +1,11 @@
+package transport
+
+import "net/http"
+
+// RoundTripperFunc adapts a function to an http.RoundTripper, e.g. to wrap
+// the transport of the BMC client.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}